@@ -0,0 +1,227 @@
+// Package analysis turns the text of the documents and the queries into the terms of the index
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball"
+)
+
+// Language is the name of the language in the snowball stemmer
+type Language string
+
+const (
+	English   Language = "english"
+	Spanish   Language = "spanish"
+	French    Language = "french"
+	Russian   Language = "russian"
+	Swedish   Language = "swedish"
+	Norwegian Language = "norwegian"
+)
+
+// DefaultLanguage is used when the language of the text can't be detected
+const DefaultLanguage = English
+
+// Languages are all the languages supported by the stemmer
+var Languages = []Language{English, Spanish, French, Russian, Swedish, Norwegian}
+
+// ErrUnknownLanguage is returned when the language isn't supported
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// ParseLanguage returns the supported language by its name
+func ParseLanguage(name string) (Language, error) {
+	for _, l := range Languages {
+		if string(l) == strings.ToLower(name) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownLanguage, name)
+}
+
+// LanguageOf returns the language by its name or the detected language of the text if the name is empty
+func LanguageOf(name, text string) (Language, error) {
+	if name == "" {
+		return Detect(text), nil
+	}
+	return ParseLanguage(name)
+}
+
+// Tokenizer splits the text into tokens
+type Tokenizer func(text string) []string
+
+// Filter transforms the token, the token is dropped if the result is empty
+type Filter func(token string) (string, error)
+
+// Chain splits the text with the tokenizer and passes every token through the filters in order
+type Chain struct {
+	Tokenizer Tokenizer
+	Filters   []Filter
+	// Whole reports whether the terms depend on the whole text, so the text must not be analyzed in pieces
+	Whole bool
+}
+
+// ForLanguage returns the chain of the letter tokenizer, lowercase, stop words and stemmer of the language
+func ForLanguage(lang Language) *Chain {
+	return &Chain{
+		Tokenizer: LetterTokenizer,
+		Filters:   []Filter{Lowercase, StopWords(lang), Stemmer(lang, false)},
+	}
+}
+
+// WholeText reports whether the analyzer must get the whole text at once, because the terms of the text can't be made
+// of the terms of its pieces
+func WholeText(a Analyzer) bool {
+	c, ok := a.(*Chain)
+	return ok && c.Whole
+}
+
+// Analyze returns the terms of the text
+func (c *Chain) Analyze(text string) ([]string, error) {
+	terms, _, err := c.run(text)
+	return terms, err
+}
+
+// AnalyzeGaps returns the terms of the text in place of their tokens, the dropped tokens such as the stop words are
+// left as empty strings. The index of the term is the position of its token, so the terms which are separated by the
+// dropped tokens aren't adjacent
+func (c *Chain) AnalyzeGaps(text string) ([]string, error) {
+	return c.gaps(c.Tokenizer(text))
+}
+
+// AnalyzeSurfaces returns the terms of the text like AnalyzeGaps does and the surface forms of the terms in place of
+// the terms. The surface form is the lowercase token the term is made of, it's empty for the dropped tokens
+func (c *Chain) AnalyzeSurfaces(text string) (terms, surfaces []string, err error) {
+	tokens := c.Tokenizer(text)
+	if terms, err = c.gaps(tokens); err != nil {
+		return nil, nil, err
+	}
+	surfaces = make([]string, len(tokens))
+	for i, t := range terms {
+		if t != "" {
+			surfaces[i] = strings.ToLower(tokens[i])
+		}
+	}
+	return terms, surfaces, nil
+}
+
+// gaps passes the tokens through the filters, the dropped tokens are left as empty strings
+func (c *Chain) gaps(tokens []string) ([]string, error) {
+	terms := make([]string, len(tokens))
+TokenLoop:
+	for i, t := range tokens {
+		for _, f := range c.Filters {
+			filtered, err := f(t)
+			if err != nil {
+				return nil, err
+			}
+			if filtered == "" {
+				continue TokenLoop
+			}
+			t = filtered
+		}
+		terms[i] = t
+	}
+	return terms, nil
+}
+
+// gapAnalyzer is the analyzer which keeps the positions of the dropped tokens
+type gapAnalyzer interface {
+	AnalyzeGaps(text string) ([]string, error)
+}
+
+// AnalyzeGaps returns the terms of the text with the dropped tokens left as empty strings like Chain.AnalyzeGaps
+// does. The terms of the analyzers which don't keep the dropped tokens have no gaps
+func AnalyzeGaps(a Analyzer, text string) ([]string, error) {
+	if g, ok := a.(gapAnalyzer); ok {
+		return g.AnalyzeGaps(text)
+	}
+	return a.Analyze(text)
+}
+
+// surfaceAnalyzer is the analyzer which knows the tokens its terms are made of
+type surfaceAnalyzer interface {
+	AnalyzeSurfaces(text string) ([]string, []string, error)
+}
+
+// AnalyzeSurfaces returns the terms of the text with the gaps like AnalyzeGaps does and their surface forms like
+// Chain.AnalyzeSurfaces does. The surface forms of the analyzers which don't keep the tokens are the terms themselves
+func AnalyzeSurfaces(a Analyzer, text string) (terms, surfaces []string, err error) {
+	if s, ok := a.(surfaceAnalyzer); ok {
+		return s.AnalyzeSurfaces(text)
+	}
+	if terms, err = AnalyzeGaps(a, text); err != nil {
+		return nil, nil, err
+	}
+	return terms, append([]string(nil), terms...), nil
+}
+
+// Dropped returns the tokens of the text which are dropped by the filters, such as the stop words. Every token is
+// returned as it was passed to the filter which dropped it
+func (c *Chain) Dropped(text string) ([]string, error) {
+	_, dropped, err := c.run(text)
+	return dropped, err
+}
+
+// run passes the tokens of the text through the filters and returns the terms and the dropped tokens
+func (c *Chain) run(text string) (terms, dropped []string, err error) {
+	tokens := c.Tokenizer(text)
+
+	terms = make([]string, 0, len(tokens))
+TokenLoop:
+	for _, t := range tokens {
+		for _, f := range c.Filters {
+			filtered, err := f(t)
+			if err != nil {
+				return nil, nil, err
+			}
+			if filtered == "" {
+				dropped = append(dropped, t)
+				continue TokenLoop
+			}
+			t = filtered
+		}
+		terms = append(terms, t)
+	}
+	return terms, dropped, nil
+}
+
+// LetterTokenizer splits the text on everything except letters and apostrophes
+func LetterTokenizer(text string) []string {
+	return strings.FieldsFunc(text, func(c rune) bool {
+		return !unicode.IsLetter(c) && c != '\''
+	})
+}
+
+// Lowercase returns the token in lower case
+func Lowercase(token string) (string, error) {
+	return strings.ToLower(token), nil
+}
+
+// StopWords returns the filter which drops the stop words of the language
+func StopWords(lang Language) Filter {
+	return func(token string) (string, error) {
+		if isStopWord(lang, token) {
+			return "", nil
+		}
+		return token, nil
+	}
+}
+
+// Stemmer returns the filter which replaces the token with its stem in the language. The stemmer keeps the words of
+// its own stop word list as they are unless stemStopWords is set
+func Stemmer(lang Language, stemStopWords bool) Filter {
+	return func(token string) (string, error) {
+		stemmed, err := snowball.Stem(token, string(lang), stemStopWords)
+		if err != nil {
+			return "", fmt.Errorf("error while stemming the word: %w", err)
+		}
+		return stemmed, nil
+	}
+}
+
+func isStopWord(lang Language, word string) bool {
+	return stopWords[lang][word]
+}
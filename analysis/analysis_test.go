@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		lang Language
+		text string
+		want []string
+	}{
+		{
+			name: "English",
+			lang: English,
+			text: "You are Freezing the subversion",
+			want: []string{"freez", "subvers"},
+		},
+		{
+			name: "Russian",
+			lang: Russian,
+			text: "Я читаю интересные книги",
+			want: []string{"чита", "интересн", "книг"},
+		},
+		{
+			name: "Spanish",
+			lang: Spanish,
+			text: "Los niños corren en el parque",
+			want: []string{"niñ", "corr", "parqu"},
+		},
+		{
+			name: "Apostrophes and punctuation",
+			lang: English,
+			text: "Hello, world! It's golang.",
+			want: []string{"hello", "world", "golang"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForLanguage(tt.lang).Analyze(tt.text)
+			if err != nil {
+				t.Errorf("Analyze() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Language
+	}{
+		{
+			name: "English",
+			text: "The inverted index is a data structure which is used by the search engines",
+			want: English,
+		},
+		{
+			name: "Russian",
+			text: "Инвертированный индекс используется поисковыми системами",
+			want: Russian,
+		},
+		{
+			name: "French",
+			text: "Le chat est sur la table et il ne veut pas partir",
+			want: French,
+		},
+		{
+			name: "Spanish",
+			text: "El perro come la comida que le dio su dueño",
+			want: Spanish,
+		},
+		{
+			name: "No stop words",
+			text: "golang java",
+			want: DefaultLanguage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	if got, err := ParseLanguage("Russian"); err != nil || got != Russian {
+		t.Errorf("ParseLanguage() = %v, %v, want %v", got, err, Russian)
+	}
+	if _, err := ParseLanguage("klingon"); err == nil {
+		t.Errorf("ParseLanguage() error = nil, want ErrUnknownLanguage")
+	}
+}
+
+func TestAnalyzers(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		text     string
+		want     []string
+	}{
+		{
+			name:     "Standard",
+			settings: Settings{Analyzer: Standard},
+			text:     "The Golang, the Java",
+			want:     []string{"the", "golang", "the", "java"},
+		},
+		{
+			name:     "Whitespace",
+			settings: Settings{Analyzer: Whitespace},
+			text:     "The Golang, the Java",
+			want:     []string{"The", "Golang,", "the", "Java"},
+		},
+		{
+			name:     "Keyword",
+			settings: Settings{Analyzer: Keyword},
+			text:     " The \n Golang ",
+			want:     []string{"The Golang"},
+		},
+		{
+			name:     "Built-in stop words",
+			settings: Settings{Analyzer: Stemming},
+			text:     "I'm writing the golang",
+			want:     []string{"write", "golang"},
+		},
+		{
+			name:     "Custom stop words",
+			settings: Settings{Analyzer: Stemming, StopWords: map[Language][]string{English: {"golang"}}},
+			text:     "The golang is having fun",
+			want:     []string{"the", "is", "have", "fun"},
+		},
+		{
+			name:     "Disabled stop words",
+			settings: Settings{Analyzer: Stemming, NoStopWords: true},
+			text:     "The golang is having fun",
+			want:     []string{"the", "golang", "is", "have", "fun"},
+		},
+		{
+			name:     "Stemming with fixed language",
+			settings: Settings{Analyzer: Stemming, Language: Russian},
+			text:     "Интересные книги",
+			want:     []string{"интересн", "книг"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer, err := tt.settings.New(English)
+			if err != nil {
+				t.Errorf("New() error = %v", err)
+				return
+			}
+			got, err := analyzer.Analyze(tt.text)
+			if err != nil {
+				t.Errorf("Analyze() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultSettings().Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (Settings{Analyzer: "snowflake"}).Validate(); !errors.Is(err, ErrUnknownAnalyzer) {
+		t.Errorf("Validate() error = %v, want ErrUnknownAnalyzer", err)
+	}
+	if err := (Settings{Analyzer: Stemming, Language: "klingon"}).Validate(); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("Validate() error = %v, want ErrUnknownLanguage", err)
+	}
+}
+
+func TestReadStopWords(t *testing.T) {
+	list := "| Snowball comment\nthe | article\n# Lucene comment\nOf and\n\n"
+	got, err := ReadStopWords(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("ReadStopWords() error = %v", err)
+	}
+	if want := []string{"the", "of", "and"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadStopWords() got = %v, want %v", got, want)
+	}
+}
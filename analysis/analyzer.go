@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Analyzer turns the text into the terms of the index. The same analyzer must be used for the documents and for the
+// queries to the index
+type Analyzer interface {
+	Analyze(text string) ([]string, error)
+}
+
+// Names of the built-in analyzers
+const (
+	// Standard splits the text on everything except letters and apostrophes and lowercases the words
+	Standard = "standard"
+	// Whitespace splits the text on white space and keeps the words as they are
+	Whitespace = "whitespace"
+	// Keyword keeps the whole text with the white space collapsed as a single term. Files are analyzed as a whole too,
+	// so the query matches the documents with exactly the same text
+	Keyword = "keyword"
+	// Stemming is the standard analyzer which also drops the stop words and stems the words in the language of the text
+	Stemming = "stemming"
+)
+
+// ErrUnknownAnalyzer is returned when the settings have no built-in analyzer with such name
+var ErrUnknownAnalyzer = errors.New("unknown analyzer")
+
+// Settings describe the analyzer of the index. They are stored with the index, so the queries are analyzed the same
+// way as the documents
+type Settings struct {
+	// Analyzer is the name of the built-in analyzer
+	Analyzer string `json:"analyzer"`
+	// Language is the language of the stemming analyzer, it's detected for every document and query if it's empty
+	Language Language `json:"language,omitempty"`
+	// StopWords are the custom stop word lists of the stemming analyzer, the built-in list is used for the languages
+	// which aren't in the map
+	StopWords map[Language][]string `json:"stopWords,omitempty"`
+	// NoStopWords disables the stop words of the stemming analyzer
+	NoStopWords bool `json:"noStopWords,omitempty"`
+}
+
+// DefaultSettings returns the settings of the stemming analyzer with language detection
+func DefaultSettings() Settings {
+	return Settings{
+		Analyzer: Stemming,
+	}
+}
+
+// Validate returns the error if the settings have unknown analyzer or language
+func (s Settings) Validate() error {
+	switch s.Analyzer {
+	case Standard, Whitespace, Keyword, Stemming:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownAnalyzer, s.Analyzer)
+	}
+	if s.Language != "" {
+		if _, err := ParseLanguage(string(s.Language)); err != nil {
+			return err
+		}
+	}
+	for lang := range s.StopWords {
+		if _, err := ParseLanguage(string(lang)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equal reports whether the settings analyze the text the same way
+func (s Settings) Equal(other Settings) bool {
+	return reflect.DeepEqual(s, other)
+}
+
+// LanguageOf returns the language of the settings if it's fixed, otherwise the language by its name or the detected
+// language of the text if the name is empty
+func (s Settings) LanguageOf(name, text string) (Language, error) {
+	if s.Language != "" {
+		return s.Language, nil
+	}
+	return LanguageOf(name, text)
+}
+
+// New returns the analyzer of the text in the language. The language is used only by the stemming analyzer
+func (s Settings) New(lang Language) (Analyzer, error) {
+	switch s.Analyzer {
+	case Standard:
+		return &Chain{
+			Tokenizer: LetterTokenizer,
+			Filters:   []Filter{Lowercase},
+		}, nil
+	case Whitespace:
+		return &Chain{
+			Tokenizer: strings.Fields,
+		}, nil
+	case Keyword:
+		return &Chain{
+			Tokenizer: KeywordTokenizer,
+			Whole:     true,
+		}, nil
+	case Stemming:
+		if s.Language != "" {
+			lang = s.Language
+		}
+		words, custom := s.StopWords[lang]
+		if !custom && !s.NoStopWords {
+			return ForLanguage(lang), nil
+		}
+
+		filters := []Filter{Lowercase}
+		if !s.NoStopWords {
+			filters = append(filters, StopWordList(words))
+		}
+		// the stemmer doesn't skip its own stop words, because the custom list is used instead of them
+		return &Chain{
+			Tokenizer: LetterTokenizer,
+			Filters:   append(filters, Stemmer(lang, true)),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAnalyzer, s.Analyzer)
+	}
+}
+
+// NormalizePattern returns the wildcard or range pattern written the way the analyzer writes the terms, so it can be
+// compared with the terms of the index. The wildcards '*' and '?' are kept, the words aren't stemmed
+func (s Settings) NormalizePattern(pattern string) string {
+	switch s.Analyzer {
+	case Whitespace:
+		return pattern
+	case Keyword:
+		return strings.Join(strings.Fields(pattern), " ")
+	default:
+		// the letter tokenizer keeps only the letters and apostrophes, which are lowercased by the standard and
+		// stemming analyzers
+		return strings.Map(func(r rune) rune {
+			if r == '*' || r == '?' || r == '\'' || unicode.IsLetter(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, pattern)
+	}
+}
+
+// KeywordTokenizer returns the whole text with the runs of white space replaced by single spaces as a single token
+func KeywordTokenizer(text string) []string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+	return []string{strings.Join(fields, " ")}
+}
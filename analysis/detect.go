@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Detect returns the most probable language of the text. The text written mostly in Cyrillic is Russian, otherwise
+// it's the language whose stop words occur in the text most often. DefaultLanguage is returned if there are no stop
+// words in the text
+func Detect(text string) Language {
+
+	var cyrillic, latin int
+	for _, c := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, c):
+			cyrillic++
+		case unicode.Is(unicode.Latin, c):
+			latin++
+		}
+	}
+	if cyrillic > latin {
+		return Russian
+	}
+
+	hits := make(map[Language]int, len(Languages))
+	for _, t := range LetterTokenizer(text) {
+		t = strings.ToLower(t)
+		for _, l := range Languages {
+			if l != Russian && isStopWord(l, t) {
+				hits[l]++
+			}
+		}
+	}
+
+	// ties are resolved in the order of the languages, so the default language wins them
+	ans := DefaultLanguage
+	for _, l := range Languages {
+		if hits[l] > hits[ans] {
+			ans = l
+		}
+	}
+	return ans
+}
@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadStopWords returns the stop words of the list with one or several words per line. The text after '#' or '|' is
+// a comment, so the lists in the Snowball and Lucene formats can be read
+func ReadStopWords(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexAny(line, "#|"); i >= 0 {
+			line = line[:i]
+		}
+		for _, w := range strings.Fields(line) {
+			words = append(words, strings.ToLower(w))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// LoadStopWords returns the stop words of the list file
+func LoadStopWords(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadStopWords(file)
+}
+
+// StopWordList returns the filter which drops the lowercase words of the list
+func StopWordList(words []string) Filter {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[strings.ToLower(w)] = true
+	}
+	return func(token string) (string, error) {
+		if set[token] {
+			return "", nil
+		}
+		return token, nil
+	}
+}
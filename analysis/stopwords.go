@@ -0,0 +1,1240 @@
+package analysis
+
+// stopWords are the built-in stop words of the languages, the lists of the languages except English are the ones used
+// by the snowball stemmer
+var stopWords = map[Language]map[string]bool{
+	English: {
+		"a":            true,
+		"about":        true,
+		"above":        true,
+		"across":       true,
+		"after":        true,
+		"afterwards":   true,
+		"again":        true,
+		"against":      true,
+		"all":          true,
+		"almost":       true,
+		"alone":        true,
+		"along":        true,
+		"already":      true,
+		"also":         true,
+		"although":     true,
+		"always":       true,
+		"am":           true,
+		"among":        true,
+		"amongst":      true,
+		"amoungst":     true,
+		"amount":       true,
+		"an":           true,
+		"and":          true,
+		"another":      true,
+		"any":          true,
+		"anyhow":       true,
+		"anyone":       true,
+		"anything":     true,
+		"anyway":       true,
+		"anywhere":     true,
+		"are":          true,
+		"around":       true,
+		"as":           true,
+		"at":           true,
+		"back":         true,
+		"be":           true,
+		"became":       true,
+		"because":      true,
+		"become":       true,
+		"becomes":      true,
+		"becoming":     true,
+		"been":         true,
+		"before":       true,
+		"beforehand":   true,
+		"behind":       true,
+		"being":        true,
+		"below":        true,
+		"beside":       true,
+		"besides":      true,
+		"between":      true,
+		"beyond":       true,
+		"bill":         true,
+		"both":         true,
+		"bottom":       true,
+		"but":          true,
+		"by":           true,
+		"call":         true,
+		"can":          true,
+		"cannot":       true,
+		"cant":         true,
+		"co":           true,
+		"con":          true,
+		"could":        true,
+		"couldnt":      true,
+		"cry":          true,
+		"de":           true,
+		"describe":     true,
+		"detail":       true,
+		"do":           true,
+		"done":         true,
+		"down":         true,
+		"due":          true,
+		"during":       true,
+		"each":         true,
+		"eg":           true,
+		"eight":        true,
+		"either":       true,
+		"eleven":       true,
+		"else":         true,
+		"elsewhere":    true,
+		"empty":        true,
+		"enough":       true,
+		"etc":          true,
+		"even":         true,
+		"ever":         true,
+		"every":        true,
+		"everyone":     true,
+		"everything":   true,
+		"everywhere":   true,
+		"except":       true,
+		"few":          true,
+		"fifteen":      true,
+		"fify":         true,
+		"fill":         true,
+		"find":         true,
+		"fire":         true,
+		"first":        true,
+		"five":         true,
+		"for":          true,
+		"former":       true,
+		"formerly":     true,
+		"forty":        true,
+		"found":        true,
+		"four":         true,
+		"from":         true,
+		"front":        true,
+		"full":         true,
+		"further":      true,
+		"get":          true,
+		"give":         true,
+		"go":           true,
+		"had":          true,
+		"has":          true,
+		"hasnt":        true,
+		"have":         true,
+		"he":           true,
+		"hence":        true,
+		"her":          true,
+		"here":         true,
+		"hereafter":    true,
+		"hereby":       true,
+		"herein":       true,
+		"hereupon":     true,
+		"hers":         true,
+		"herself":      true,
+		"him":          true,
+		"himself":      true,
+		"his":          true,
+		"how":          true,
+		"however":      true,
+		"hundred":      true,
+		"ie":           true,
+		"if":           true,
+		"in":           true,
+		"inc":          true,
+		"indeed":       true,
+		"interest":     true,
+		"into":         true,
+		"is":           true,
+		"it":           true,
+		"its":          true,
+		"itself":       true,
+		"keep":         true,
+		"last":         true,
+		"latter":       true,
+		"latterly":     true,
+		"least":        true,
+		"less":         true,
+		"ltd":          true,
+		"made":         true,
+		"many":         true,
+		"may":          true,
+		"me":           true,
+		"meanwhile":    true,
+		"might":        true,
+		"mill":         true,
+		"mine":         true,
+		"more":         true,
+		"moreover":     true,
+		"most":         true,
+		"mostly":       true,
+		"move":         true,
+		"much":         true,
+		"must":         true,
+		"my":           true,
+		"myself":       true,
+		"name":         true,
+		"namely":       true,
+		"neither":      true,
+		"never":        true,
+		"nevertheless": true,
+		"next":         true,
+		"nine":         true,
+		"no":           true,
+		"nobody":       true,
+		"none":         true,
+		"noone":        true,
+		"nor":          true,
+		"not":          true,
+		"nothing":      true,
+		"now":          true,
+		"nowhere":      true,
+		"of":           true,
+		"off":          true,
+		"often":        true,
+		"on":           true,
+		"once":         true,
+		"one":          true,
+		"only":         true,
+		"onto":         true,
+		"or":           true,
+		"other":        true,
+		"others":       true,
+		"otherwise":    true,
+		"our":          true,
+		"ours":         true,
+		"ourselves":    true,
+		"out":          true,
+		"over":         true,
+		"own":          true,
+		"part":         true,
+		"per":          true,
+		"perhaps":      true,
+		"please":       true,
+		"put":          true,
+		"rather":       true,
+		"re":           true,
+		"same":         true,
+		"see":          true,
+		"seem":         true,
+		"seemed":       true,
+		"seeming":      true,
+		"seems":        true,
+		"serious":      true,
+		"several":      true,
+		"she":          true,
+		"should":       true,
+		"show":         true,
+		"side":         true,
+		"since":        true,
+		"sincere":      true,
+		"six":          true,
+		"sixty":        true,
+		"so":           true,
+		"some":         true,
+		"somehow":      true,
+		"someone":      true,
+		"something":    true,
+		"sometime":     true,
+		"sometimes":    true,
+		"somewhere":    true,
+		"still":        true,
+		"such":         true,
+		"system":       true,
+		"take":         true,
+		"ten":          true,
+		"than":         true,
+		"that":         true,
+		"the":          true,
+		"their":        true,
+		"them":         true,
+		"themselves":   true,
+		"then":         true,
+		"thence":       true,
+		"there":        true,
+		"thereafter":   true,
+		"thereby":      true,
+		"therefore":    true,
+		"therein":      true,
+		"thereupon":    true,
+		"these":        true,
+		"they":         true,
+		"thickv":       true,
+		"thin":         true,
+		"third":        true,
+		"this":         true,
+		"those":        true,
+		"though":       true,
+		"three":        true,
+		"through":      true,
+		"throughout":   true,
+		"thru":         true,
+		"thus":         true,
+		"to":           true,
+		"together":     true,
+		"too":          true,
+		"top":          true,
+		"toward":       true,
+		"towards":      true,
+		"twelve":       true,
+		"twenty":       true,
+		"two":          true,
+		"un":           true,
+		"under":        true,
+		"until":        true,
+		"up":           true,
+		"upon":         true,
+		"us":           true,
+		"very":         true,
+		"via":          true,
+		"was":          true,
+		"we":           true,
+		"well":         true,
+		"were":         true,
+		"what":         true,
+		"whatever":     true,
+		"when":         true,
+		"whence":       true,
+		"whenever":     true,
+		"where":        true,
+		"whereafter":   true,
+		"whereas":      true,
+		"whereby":      true,
+		"wherein":      true,
+		"whereupon":    true,
+		"wherever":     true,
+		"whether":      true,
+		"which":        true,
+		"while":        true,
+		"whither":      true,
+		"who":          true,
+		"whoever":      true,
+		"whole":        true,
+		"whom":         true,
+		"whose":        true,
+		"why":          true,
+		"will":         true,
+		"with":         true,
+		"within":       true,
+		"without":      true,
+		"would":        true,
+		"yet":          true,
+		"you":          true,
+		"your":         true,
+		"yours":        true,
+		"yourself":     true,
+		"yourselves":   true,
+		"couldn't":     true,
+		"did":          true,
+		"didnt":        true,
+		"does":         true,
+		"doesn't":      true,
+		"doing":        true,
+		"don't":        true,
+		"hadn't":       true,
+		"hasn't":       true,
+		"haven't":      true,
+		"having":       true,
+		"hed":          true,
+		"hell":         true,
+		"hes":          true,
+		"hows":         true,
+		"i":            true,
+		"i'd":          true,
+		"i'll":         true,
+		"i'm":          true,
+		"i've":         true,
+		"isn't":        true,
+		"it's":         true,
+		"lets":         true,
+		"mustn't":      true,
+		"ought":        true,
+		"shan't":       true,
+		"she'd":        true,
+		"shell":        true,
+		"she's":        true,
+		"shouldn't":    true,
+		"that's":       true,
+		"theirs":       true,
+		"there's":      true,
+		"they'd":       true,
+		"they'll":      true,
+		"they're":      true,
+		"they've":      true,
+		"wasn't":       true,
+		"we'd":         true,
+		"we'll":        true,
+		"we've":        true,
+		"weren't":      true,
+		"what's":       true,
+		"when's":       true,
+		"where's":      true,
+		"who's":        true,
+		"why's":        true,
+		"won't":        true,
+		"wouldn't":     true,
+		"you'd":        true,
+		"you'll":       true,
+		"you're":       true,
+		"you've":       true,
+	},
+	Spanish: {
+		"de":           true,
+		"la":           true,
+		"que":          true,
+		"el":           true,
+		"en":           true,
+		"y":            true,
+		"a":            true,
+		"los":          true,
+		"del":          true,
+		"se":           true,
+		"las":          true,
+		"por":          true,
+		"un":           true,
+		"para":         true,
+		"con":          true,
+		"no":           true,
+		"una":          true,
+		"su":           true,
+		"al":           true,
+		"lo":           true,
+		"como":         true,
+		"más":          true,
+		"pero":         true,
+		"sus":          true,
+		"le":           true,
+		"ya":           true,
+		"o":            true,
+		"este":         true,
+		"sí":           true,
+		"porque":       true,
+		"esta":         true,
+		"entre":        true,
+		"cuando":       true,
+		"muy":          true,
+		"sin":          true,
+		"sobre":        true,
+		"también":      true,
+		"me":           true,
+		"hasta":        true,
+		"hay":          true,
+		"donde":        true,
+		"quien":        true,
+		"desde":        true,
+		"todo":         true,
+		"nos":          true,
+		"durante":      true,
+		"todos":        true,
+		"uno":          true,
+		"les":          true,
+		"ni":           true,
+		"contra":       true,
+		"otros":        true,
+		"ese":          true,
+		"eso":          true,
+		"ante":         true,
+		"ellos":        true,
+		"e":            true,
+		"esto":         true,
+		"mí":           true,
+		"antes":        true,
+		"algunos":      true,
+		"qué":          true,
+		"unos":         true,
+		"yo":           true,
+		"otro":         true,
+		"otras":        true,
+		"otra":         true,
+		"él":           true,
+		"tanto":        true,
+		"esa":          true,
+		"estos":        true,
+		"mucho":        true,
+		"quienes":      true,
+		"nada":         true,
+		"muchos":       true,
+		"cual":         true,
+		"poco":         true,
+		"ella":         true,
+		"estar":        true,
+		"estas":        true,
+		"algunas":      true,
+		"algo":         true,
+		"nosotros":     true,
+		"mi":           true,
+		"mis":          true,
+		"tú":           true,
+		"te":           true,
+		"ti":           true,
+		"tu":           true,
+		"tus":          true,
+		"ellas":        true,
+		"nosotras":     true,
+		"vosostros":    true,
+		"vosostras":    true,
+		"os":           true,
+		"mío":          true,
+		"mía":          true,
+		"míos":         true,
+		"mías":         true,
+		"tuyo":         true,
+		"tuya":         true,
+		"tuyos":        true,
+		"tuyas":        true,
+		"suyo":         true,
+		"suya":         true,
+		"suyos":        true,
+		"suyas":        true,
+		"nuestro":      true,
+		"nuestra":      true,
+		"nuestros":     true,
+		"nuestras":     true,
+		"vuestro":      true,
+		"vuestra":      true,
+		"vuestros":     true,
+		"vuestras":     true,
+		"esos":         true,
+		"esas":         true,
+		"estoy":        true,
+		"estás":        true,
+		"está":         true,
+		"estamos":      true,
+		"estáis":       true,
+		"están":        true,
+		"esté":         true,
+		"estés":        true,
+		"estemos":      true,
+		"estéis":       true,
+		"estén":        true,
+		"estaré":       true,
+		"estarás":      true,
+		"estará":       true,
+		"estaremos":    true,
+		"estaréis":     true,
+		"estarán":      true,
+		"estaría":      true,
+		"estarías":     true,
+		"estaríamos":   true,
+		"estaríais":    true,
+		"estarían":     true,
+		"estaba":       true,
+		"estabas":      true,
+		"estábamos":    true,
+		"estabais":     true,
+		"estaban":      true,
+		"estuve":       true,
+		"estuviste":    true,
+		"estuvo":       true,
+		"estuvimos":    true,
+		"estuvisteis":  true,
+		"estuvieron":   true,
+		"estuviera":    true,
+		"estuvieras":   true,
+		"estuviéramos": true,
+		"estuvierais":  true,
+		"estuvieran":   true,
+		"estuviese":    true,
+		"estuvieses":   true,
+		"estuviésemos": true,
+		"estuvieseis":  true,
+		"estuviesen":   true,
+		"estando":      true,
+		"estado":       true,
+		"estada":       true,
+		"estados":      true,
+		"estadas":      true,
+		"estad":        true,
+		"he":           true,
+		"has":          true,
+		"ha":           true,
+		"hemos":        true,
+		"habéis":       true,
+		"han":          true,
+		"haya":         true,
+		"hayas":        true,
+		"hayamos":      true,
+		"hayáis":       true,
+		"hayan":        true,
+		"habré":        true,
+		"habrás":       true,
+		"habrá":        true,
+		"habremos":     true,
+		"habréis":      true,
+		"habrán":       true,
+		"habría":       true,
+		"habrías":      true,
+		"habríamos":    true,
+		"habríais":     true,
+		"habrían":      true,
+		"había":        true,
+		"habías":       true,
+		"habíamos":     true,
+		"habíais":      true,
+		"habían":       true,
+		"hube":         true,
+		"hubiste":      true,
+		"hubo":         true,
+		"hubimos":      true,
+		"hubisteis":    true,
+		"hubieron":     true,
+		"hubiera":      true,
+		"hubieras":     true,
+		"hubiéramos":   true,
+		"hubierais":    true,
+		"hubieran":     true,
+		"hubiese":      true,
+		"hubieses":     true,
+		"hubiésemos":   true,
+		"hubieseis":    true,
+		"hubiesen":     true,
+		"habiendo":     true,
+		"habido":       true,
+		"habida":       true,
+		"habidos":      true,
+		"habidas":      true,
+		"soy":          true,
+		"eres":         true,
+		"es":           true,
+		"somos":        true,
+		"sois":         true,
+		"son":          true,
+		"sea":          true,
+		"seas":         true,
+		"seamos":       true,
+		"seáis":        true,
+		"sean":         true,
+		"seré":         true,
+		"serás":        true,
+		"será":         true,
+		"seremos":      true,
+		"seréis":       true,
+		"serán":        true,
+		"sería":        true,
+		"serías":       true,
+		"seríamos":     true,
+		"seríais":      true,
+		"serían":       true,
+		"era":          true,
+		"eras":         true,
+		"éramos":       true,
+		"erais":        true,
+		"eran":         true,
+		"fui":          true,
+		"fuiste":       true,
+		"fue":          true,
+		"fuimos":       true,
+		"fuisteis":     true,
+		"fueron":       true,
+		"fuera":        true,
+		"fueras":       true,
+		"fuéramos":     true,
+		"fuerais":      true,
+		"fueran":       true,
+		"fuese":        true,
+		"fueses":       true,
+		"fuésemos":     true,
+		"fueseis":      true,
+		"fuesen":       true,
+		"sintiendo":    true,
+		"sentido":      true,
+		"sentida":      true,
+		"sentidos":     true,
+		"sentidas":     true,
+		"siente":       true,
+		"sentid":       true,
+		"tengo":        true,
+		"tienes":       true,
+		"tiene":        true,
+		"tenemos":      true,
+		"tenéis":       true,
+		"tienen":       true,
+		"tenga":        true,
+		"tengas":       true,
+		"tengamos":     true,
+		"tengáis":      true,
+		"tengan":       true,
+		"tendré":       true,
+		"tendrás":      true,
+		"tendrá":       true,
+		"tendremos":    true,
+		"tendréis":     true,
+		"tendrán":      true,
+		"tendría":      true,
+		"tendrías":     true,
+		"tendríamos":   true,
+		"tendríais":    true,
+		"tendrían":     true,
+		"tenía":        true,
+		"tenías":       true,
+		"teníamos":     true,
+		"teníais":      true,
+		"tenían":       true,
+		"tuve":         true,
+		"tuviste":      true,
+		"tuvo":         true,
+		"tuvimos":      true,
+		"tuvisteis":    true,
+		"tuvieron":     true,
+		"tuviera":      true,
+		"tuvieras":     true,
+		"tuviéramos":   true,
+		"tuvierais":    true,
+		"tuvieran":     true,
+		"tuviese":      true,
+		"tuvieses":     true,
+		"tuviésemos":   true,
+		"tuvieseis":    true,
+		"tuviesen":     true,
+		"teniendo":     true,
+		"tenido":       true,
+		"tenida":       true,
+		"tenidos":      true,
+		"tenidas":      true,
+		"tened":        true,
+	},
+	French: {
+		"au":       true,
+		"aux":      true,
+		"avec":     true,
+		"ce":       true,
+		"ces":      true,
+		"dans":     true,
+		"de":       true,
+		"des":      true,
+		"du":       true,
+		"elle":     true,
+		"en":       true,
+		"et":       true,
+		"eux":      true,
+		"il":       true,
+		"je":       true,
+		"la":       true,
+		"le":       true,
+		"leur":     true,
+		"lui":      true,
+		"ma":       true,
+		"mais":     true,
+		"me":       true,
+		"même":     true,
+		"mes":      true,
+		"moi":      true,
+		"mon":      true,
+		"ne":       true,
+		"nos":      true,
+		"notre":    true,
+		"nous":     true,
+		"on":       true,
+		"ou":       true,
+		"par":      true,
+		"pas":      true,
+		"pour":     true,
+		"qu":       true,
+		"que":      true,
+		"qui":      true,
+		"sa":       true,
+		"se":       true,
+		"ses":      true,
+		"son":      true,
+		"sur":      true,
+		"ta":       true,
+		"te":       true,
+		"tes":      true,
+		"toi":      true,
+		"ton":      true,
+		"tu":       true,
+		"un":       true,
+		"une":      true,
+		"vos":      true,
+		"votre":    true,
+		"vous":     true,
+		"c":        true,
+		"d":        true,
+		"j":        true,
+		"l":        true,
+		"à":        true,
+		"m":        true,
+		"n":        true,
+		"s":        true,
+		"t":        true,
+		"y":        true,
+		"été":      true,
+		"étée":     true,
+		"étées":    true,
+		"étés":     true,
+		"étant":    true,
+		"étante":   true,
+		"étants":   true,
+		"étantes":  true,
+		"suis":     true,
+		"es":       true,
+		"est":      true,
+		"sommes":   true,
+		"êtes":     true,
+		"sont":     true,
+		"serai":    true,
+		"seras":    true,
+		"sera":     true,
+		"serons":   true,
+		"serez":    true,
+		"seront":   true,
+		"serais":   true,
+		"serait":   true,
+		"serions":  true,
+		"seriez":   true,
+		"seraient": true,
+		"étais":    true,
+		"était":    true,
+		"étions":   true,
+		"étiez":    true,
+		"étaient":  true,
+		"fus":      true,
+		"fut":      true,
+		"fûmes":    true,
+		"fûtes":    true,
+		"furent":   true,
+		"sois":     true,
+		"soit":     true,
+		"soyons":   true,
+		"soyez":    true,
+		"soient":   true,
+		"fusse":    true,
+		"fusses":   true,
+		"fût":      true,
+		"fussions": true,
+		"fussiez":  true,
+		"fussent":  true,
+		"ayant":    true,
+		"ayante":   true,
+		"ayantes":  true,
+		"ayants":   true,
+		"eu":       true,
+		"eue":      true,
+		"eues":     true,
+		"eus":      true,
+		"ai":       true,
+		"as":       true,
+		"avons":    true,
+		"avez":     true,
+		"ont":      true,
+		"aurai":    true,
+		"auras":    true,
+		"aura":     true,
+		"aurons":   true,
+		"aurez":    true,
+		"auront":   true,
+		"aurais":   true,
+		"aurait":   true,
+		"aurions":  true,
+		"auriez":   true,
+		"auraient": true,
+		"avais":    true,
+		"avait":    true,
+		"avions":   true,
+		"aviez":    true,
+		"avaient":  true,
+		"eut":      true,
+		"eûmes":    true,
+		"eûtes":    true,
+		"eurent":   true,
+		"aie":      true,
+		"aies":     true,
+		"ait":      true,
+		"ayons":    true,
+		"ayez":     true,
+		"aient":    true,
+		"eusse":    true,
+		"eusses":   true,
+		"eût":      true,
+		"eussions": true,
+		"eussiez":  true,
+		"eussent":  true,
+	},
+	Russian: {
+		"и":       true,
+		"в":       true,
+		"во":      true,
+		"не":      true,
+		"что":     true,
+		"он":      true,
+		"на":      true,
+		"я":       true,
+		"с":       true,
+		"со":      true,
+		"как":     true,
+		"а":       true,
+		"то":      true,
+		"все":     true,
+		"она":     true,
+		"так":     true,
+		"его":     true,
+		"но":      true,
+		"да":      true,
+		"ты":      true,
+		"к":       true,
+		"у":       true,
+		"же":      true,
+		"вы":      true,
+		"за":      true,
+		"бы":      true,
+		"по":      true,
+		"только":  true,
+		"ее":      true,
+		"мне":     true,
+		"было":    true,
+		"вот":     true,
+		"от":      true,
+		"меня":    true,
+		"еще":     true,
+		"нет":     true,
+		"о":       true,
+		"из":      true,
+		"ему":     true,
+		"теперь":  true,
+		"когда":   true,
+		"даже":    true,
+		"ну":      true,
+		"вдруг":   true,
+		"ли":      true,
+		"если":    true,
+		"уже":     true,
+		"или":     true,
+		"ни":      true,
+		"быть":    true,
+		"был":     true,
+		"него":    true,
+		"до":      true,
+		"вас":     true,
+		"нибудь":  true,
+		"опять":   true,
+		"уж":      true,
+		"вам":     true,
+		"ведь":    true,
+		"там":     true,
+		"потом":   true,
+		"себя":    true,
+		"ничего":  true,
+		"ей":      true,
+		"может":   true,
+		"они":     true,
+		"тут":     true,
+		"где":     true,
+		"есть":    true,
+		"надо":    true,
+		"ней":     true,
+		"для":     true,
+		"мы":      true,
+		"тебя":    true,
+		"их":      true,
+		"чем":     true,
+		"была":    true,
+		"сам":     true,
+		"чтоб":    true,
+		"без":     true,
+		"будто":   true,
+		"чего":    true,
+		"раз":     true,
+		"тоже":    true,
+		"себе":    true,
+		"под":     true,
+		"будет":   true,
+		"ж":       true,
+		"тогда":   true,
+		"кто":     true,
+		"этот":    true,
+		"того":    true,
+		"потому":  true,
+		"этого":   true,
+		"какой":   true,
+		"совсем":  true,
+		"ним":     true,
+		"здесь":   true,
+		"этом":    true,
+		"один":    true,
+		"почти":   true,
+		"мой":     true,
+		"тем":     true,
+		"чтобы":   true,
+		"нее":     true,
+		"сейчас":  true,
+		"были":    true,
+		"куда":    true,
+		"зачем":   true,
+		"всех":    true,
+		"никогда": true,
+		"можно":   true,
+		"при":     true,
+		"наконец": true,
+		"два":     true,
+		"об":      true,
+		"другой":  true,
+		"хоть":    true,
+		"после":   true,
+		"над":     true,
+		"больше":  true,
+		"тот":     true,
+		"через":   true,
+		"эти":     true,
+		"нас":     true,
+		"про":     true,
+		"всего":   true,
+		"них":     true,
+		"какая":   true,
+		"много":   true,
+		"разве":   true,
+		"три":     true,
+		"эту":     true,
+		"моя":     true,
+		"впрочем": true,
+		"хорошо":  true,
+		"свою":    true,
+		"этой":    true,
+		"перед":   true,
+		"иногда":  true,
+		"лучше":   true,
+		"чуть":    true,
+		"том":     true,
+		"нельзя":  true,
+		"такой":   true,
+		"им":      true,
+		"более":   true,
+		"всегда":  true,
+		"конечно": true,
+		"всю":     true,
+		"между":   true,
+	},
+	Swedish: {
+		"och":    true,
+		"det":    true,
+		"att":    true,
+		"i":      true,
+		"en":     true,
+		"jag":    true,
+		"hon":    true,
+		"som":    true,
+		"han":    true,
+		"på":     true,
+		"den":    true,
+		"med":    true,
+		"var":    true,
+		"sig":    true,
+		"för":    true,
+		"så":     true,
+		"till":   true,
+		"är":     true,
+		"men":    true,
+		"ett":    true,
+		"om":     true,
+		"hade":   true,
+		"de":     true,
+		"av":     true,
+		"icke":   true,
+		"mig":    true,
+		"du":     true,
+		"henne":  true,
+		"då":     true,
+		"sin":    true,
+		"nu":     true,
+		"har":    true,
+		"inte":   true,
+		"hans":   true,
+		"honom":  true,
+		"skulle": true,
+		"hennes": true,
+		"där":    true,
+		"min":    true,
+		"man":    true,
+		"ej":     true,
+		"vid":    true,
+		"kunde":  true,
+		"något":  true,
+		"från":   true,
+		"ut":     true,
+		"när":    true,
+		"efter":  true,
+		"upp":    true,
+		"vi":     true,
+		"dem":    true,
+		"vara":   true,
+		"vad":    true,
+		"över":   true,
+		"än":     true,
+		"dig":    true,
+		"kan":    true,
+		"sina":   true,
+		"här":    true,
+		"ha":     true,
+		"mot":    true,
+		"alla":   true,
+		"under":  true,
+		"någon":  true,
+		"eller":  true,
+		"allt":   true,
+		"mycket": true,
+		"sedan":  true,
+		"ju":     true,
+		"denna":  true,
+		"själv":  true,
+		"detta":  true,
+		"åt":     true,
+		"utan":   true,
+		"varit":  true,
+		"hur":    true,
+		"ingen":  true,
+		"mitt":   true,
+		"ni":     true,
+		"bli":    true,
+		"blev":   true,
+		"oss":    true,
+		"din":    true,
+		"dessa":  true,
+		"några":  true,
+		"deras":  true,
+		"blir":   true,
+		"mina":   true,
+		"samma":  true,
+		"vilken": true,
+		"er":     true,
+		"sådan":  true,
+		"vår":    true,
+		"blivit": true,
+		"dess":   true,
+		"inom":   true,
+		"mellan": true,
+		"sådant": true,
+		"varför": true,
+		"varje":  true,
+		"vilka":  true,
+		"ditt":   true,
+		"vem":    true,
+		"vilket": true,
+		"sitta":  true,
+		"sådana": true,
+		"vart":   true,
+		"dina":   true,
+		"vars":   true,
+		"vårt":   true,
+		"våra":   true,
+		"ert":    true,
+		"era":    true,
+		"vilkas": true,
+	},
+	Norwegian: {
+		"ut":       true,
+		"få":       true,
+		"hadde":    true,
+		"hva":      true,
+		"tilbake":  true,
+		"vil":      true,
+		"han":      true,
+		"meget":    true,
+		"men":      true,
+		"vi":       true,
+		"en":       true,
+		"før":      true,
+		"samme":    true,
+		"stille":   true,
+		"inn":      true,
+		"er":       true,
+		"kan":      true,
+		"makt":     true,
+		"ved":      true,
+		"forsøke":  true,
+		"hvis":     true,
+		"part":     true,
+		"rett":     true,
+		"måte":     true,
+		"denne":    true,
+		"mer":      true,
+		"i":        true,
+		"lang":     true,
+		"ny":       true,
+		"hans":     true,
+		"hvilken":  true,
+		"tid":      true,
+		"vite":     true,
+		"her":      true,
+		"opp":      true,
+		"var":      true,
+		"navn":     true,
+		"mye":      true,
+		"om":       true,
+		"sant":     true,
+		"tilstand": true,
+		"der":      true,
+		"ikke":     true,
+		"mest":     true,
+		"punkt":    true,
+		"hvem":     true,
+		"skulle":   true,
+		"mange":    true,
+		"over":     true,
+		"vårt":     true,
+		"alle":     true,
+		"arbeid":   true,
+		"lik":      true,
+		"like":     true,
+		"gå":       true,
+		"når":      true,
+		"siden":    true,
+		"å":        true,
+		"begge":    true,
+		"bruke":    true,
+		"eller":    true,
+		"og":       true,
+		"til":      true,
+		"da":       true,
+		"et":       true,
+		"hvorfor":  true,
+		"nå":       true,
+		"sist":     true,
+		"slutt":    true,
+		"deres":    true,
+		"det":      true,
+		"hennes":   true,
+		"så":       true,
+		"mens":     true,
+		"bra":      true,
+		"din":      true,
+		"fordi":    true,
+		"gjøre":    true,
+		"god":      true,
+		"ha":       true,
+		"start":    true,
+		"andre":    true,
+		"må":       true,
+		"med":      true,
+		"under":    true,
+		"meg":      true,
+		"oss":      true,
+		"innen":    true,
+		"på":       true,
+		"verdi":    true,
+		"ville":    true,
+		"kunne":    true,
+		"uten":     true,
+		"vår":      true,
+		"slik":     true,
+		"ene":      true,
+		"folk":     true,
+		"min":      true,
+		"riktig":   true,
+		"enhver":   true,
+		"bort":     true,
+		"enn":      true,
+		"nei":      true,
+		"som":      true,
+		"våre":     true,
+		"disse":    true,
+		"gjorde":   true,
+		"lage":     true,
+		"si":       true,
+		"du":       true,
+		"fra":      true,
+		"også":     true,
+		"hvordan":  true,
+		"av":       true,
+		"eneste":   true,
+		"for":      true,
+		"hvor":     true,
+		"først":    true,
+		"hver":     true,
+	},
+}
@@ -1,19 +1,81 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Stores of the index
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+	StoreFile   = "file"
+)
 
 type Config struct {
-	DbListen string
-	Listen   string
-	LogLevel string
+	// Store is the storage of the index: redis, memory or file
+	Store string
+	// IndexFile is the segment file of the file store
+	IndexFile string
+	DbListen  string
+	// RedisBatchSize is the number of commands written to Redis in one transaction
+	RedisBatchSize int
+	// Analyzer is the name of the analyzer of the built index: standard, whitespace, keyword or stemming
+	Analyzer string
+	// Language is the language of the stemming analyzer, it's detected for every document if it's empty
+	Language string
+	// StopWordFiles are the files with the custom stop word lists where key is the language
+	StopWordFiles map[string]string
+	// StopWords are the custom stop word lists where key is the language
+	StopWords map[string][]string
+	// StopWordsDisabled disables the stop words of the stemming analyzer
+	StopWordsDisabled bool
+	// SynonymsFile is the synonym dictionary applied to the queries, queries aren't expanded if it's empty
+	SynonymsFile string
+	// SynonymWeight is the score weight of the synonyms relative to the words of the query
+	SynonymWeight float64
+	// TypoTolerant makes the search tolerate typos in the query words by default, the requests enable or disable it with
+	// the fuzzy parameter
+	TypoTolerant bool
+	// MaxExpansions is the maximum number of the index terms one fuzzy, wildcard or range query term is expanded to
+	MaxExpansions int
+	// SuggestLimit is the maximum number of the completions returned by the autocomplete
+	SuggestLimit int
+	// QueryLogSize is the maximum number of the distinct past queries used by the autocomplete
+	QueryLogSize int
+	// SnippetSize is the number of characters in the snippet of the search result
+	SnippetSize int
+	// SnippetCount is the maximum number of the snippets of the search result
+	SnippetCount int
+	// PageSize is the default number of the search results in one response
+	PageSize int
+	// SourcesDir is the directory of the indexed files, the files are added to the index through the API only from it
+	SourcesDir string
+	Listen     string
+	LogLevel   string
+	// BM25K1 and BM25B are the parameters of the BM25 ranking function
+	BM25K1 float64
+	BM25B  float64
 }
 
 func Load() *Config {
-	var dbListen, listen, logLevel string
+	var store, indexFile, dbListen, listen, logLevel, analyzer string
 
-	if dbListen = os.Getenv("DB_LISTEN"); listen == "" {
+	if store = os.Getenv("STORE"); store == "" {
+		store = StoreRedis
+	}
+	if indexFile = os.Getenv("INDEX_FILE"); indexFile == "" {
+		indexFile = "index.seg"
+	}
+	if dbListen = os.Getenv("DB_LISTEN"); dbListen == "" {
 		dbListen = "redis:6379"
 	}
+	if analyzer = os.Getenv("ANALYZER"); analyzer == "" {
+		analyzer = "stemming"
+	}
 	if listen = os.Getenv("LISTEN"); listen == "" {
 		listen = "localhost:8888"
 	}
@@ -23,8 +85,103 @@ func Load() *Config {
 	}
 
 	return &Config{
-		DbListen: dbListen,
-		Listen:   listen,
-		LogLevel: logLevel,
+		Store:             store,
+		IndexFile:         indexFile,
+		DbListen:          dbListen,
+		RedisBatchSize:    loadInt("REDIS_BATCH_SIZE", 1000),
+		Analyzer:          analyzer,
+		Language:          os.Getenv("ANALYZER_LANGUAGE"),
+		StopWordFiles:     loadMap("STOPWORD_FILES"),
+		StopWords:         loadLists("STOPWORDS"),
+		StopWordsDisabled: loadBool("STOPWORDS_DISABLED", false),
+		SynonymsFile:      os.Getenv("SYNONYMS_FILE"),
+		SynonymWeight:     loadFloat("SYNONYM_WEIGHT", 0.5),
+		TypoTolerant:      loadBool("TYPO_TOLERANT", false),
+		MaxExpansions:     loadInt("MAX_EXPANSIONS", 50),
+		SuggestLimit:      loadInt("SUGGEST_LIMIT", 10),
+		QueryLogSize:      loadInt("QUERY_LOG_SIZE", 10000),
+		SnippetSize:       loadInt("SNIPPET_SIZE", 150),
+		SnippetCount:      loadInt("SNIPPET_COUNT", 2),
+		PageSize:          loadInt("PAGE_SIZE", 10),
+		SourcesDir:        os.Getenv("SOURCES_DIR"),
+		Listen:            listen,
+		LogLevel:          logLevel,
+		BM25K1:            loadFloat("BM25_K1", 1.2),
+		BM25B:             loadFloat("BM25_B", 0.75),
+	}
+}
+
+// loadFloat returns the float value of the environment variable or the default value if it's not set or invalid
+func loadFloat(key string, def float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Float64("default", def).Msg("invalid float value, default is used")
+		return def
+	}
+	return v
+}
+
+// loadInt returns the int value of the environment variable or the default value if it's not set or invalid
+func loadInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Int("default", def).Msg("invalid int value, default is used")
+		return def
+	}
+	return v
+}
+
+// loadBool returns the bool value of the environment variable or the default value if it's not set or invalid
+func loadBool(key string, def bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Bool("default", def).Msg("invalid bool value, default is used")
+		return def
+	}
+	return v
+}
+
+// loadMap returns the map from the environment variable in the "key=value,key=value" format. Invalid entries are
+// skipped
+func loadMap(key string) map[string]string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	ans := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			log.Warn().Str("key", key).Str("entry", entry).Msg("invalid map entry is skipped")
+			continue
+		}
+		ans[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return ans
+}
+
+// loadLists returns the map of the space separated lists from the environment variable in the
+// "key=a b c,key=d e" format
+func loadLists(key string) map[string][]string {
+	m := loadMap(key)
+	if m == nil {
+		return nil
+	}
+	ans := make(map[string][]string, len(m))
+	for k, v := range m {
+		ans[k] = strings.Fields(v)
 	}
+	return ans
 }
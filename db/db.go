@@ -1,73 +1,125 @@
 package db
 
 import (
-	"encoding/json"
+	"fmt"
+	"os"
 
-	"github.com/go-redis/redis/v7"
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/polisgo2020/search-Arkronzxc/config"
 	"github.com/polisgo2020/search-Arkronzxc/index"
-	"github.com/rs/zerolog/log"
 )
 
-type IndexRepository struct {
-	c *redis.Client
+// ErrNotFound is returned when the document isn't indexed
+var ErrNotFound = index.ErrNotFound
+
+// Store is the storage of the index
+type Store interface {
+	// SaveIndex writes the whole index to the store
+	SaveIndex(i index.Index) error
+	// UpdateIndex applies the changes to the stored index. The index must contain the reindexed files of the changes,
+	// changed files keep their stored document IDs and added files get new ones
+	UpdateIndex(i index.Index, changes *index.Changes) error
+	// GetIndex returns the index with the postings of the words, the documents and the analysis settings. The whole
+	// document table is returned if allDocs is set, otherwise the store may return only the documents of the postings
+	// together with the statistics of all the documents. The words which aren't in the index are returned as missing
+	GetIndex(wordArr []string, allDocs bool) (*index.Index, []string, error)
+	// GetDocuments returns the document table of the index
+	GetDocuments() (map[int]*index.Document, error)
+	// RemoveDocument removes the document and its postings from the stored index
+	RemoveDocument(id int) error
+	// UpdateDocument reindexes the document keeping its ID
+	UpdateDocument(id int) (*index.Document, error)
+	// Terms returns the sorted stored terms
+	Terms() ([]string, error)
+	// Dictionary returns the dictionary of the stored terms for the fuzzy lookups
+	Dictionary() (*index.Dictionary, error)
+	// Stats returns the statistics of the stored index
+	Stats() (*Stats, error)
+	// Settings returns the analysis settings of the stored index, the default settings if there is no index yet
+	Settings() (analysis.Settings, error)
+	Close() error
 }
 
-func NewIndexRepository(conf *config.Config) (*IndexRepository, error) {
-	cli := redis.NewClient(&redis.Options{
-		Addr:     conf.DbListen,
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+// Stats is the statistics of the stored index
+type Stats struct {
+	Documents int `json:"documents"`
+	Terms     int `json:"terms"`
+	// TotalLength is the number of words in all the documents
+	TotalLength int `json:"totalLength"`
+}
 
-	pong, err := cli.Ping().Result()
-	if err != nil {
-		log.Err(err)
-		return nil, err
+// NewStore returns the store chosen in the config
+func NewStore(conf *config.Config) (Store, error) {
+	switch conf.Store {
+	case config.StoreRedis:
+		return NewRedisStore(conf)
+	case config.StoreMemory:
+		return NewMemoryStore(), nil
+	case config.StoreFile:
+		return NewFileStore(conf.IndexFile)
+	default:
+		return nil, fmt.Errorf("unknown store %s", conf.Store)
 	}
-	log.Info().Str("result", pong).Msg("connection successful")
-	return &IndexRepository{
-		c: cli,
-	}, nil
 }
 
-func (rep *IndexRepository) SaveIndex(i index.Index) error {
-	for k, v := range i {
-		finalJson, err := json.Marshal(v)
-		if err != nil {
-			log.Err(err)
-			rep.c.FlushDB()
-			return err
-		}
-		err = rep.c.Set(k, finalJson, 0).Err()
-		if err != nil {
-			log.Err(err).Str("key", k).Interface("value", v).Msg("error while setting values into DB")
-			rep.c.FlushDB()
-			log.Debug().Msg("db is cleaned")
-			return err
+// AddDocument indexes the file of the root directory in the store. The indexed file is reindexed keeping its ID,
+// otherwise it's added with the new ID, added reports whether the file wasn't indexed before
+func AddDocument(s Store, filename, root string) (doc *index.Document, added bool, err error) {
+	docs, err := s.GetDocuments()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, d := range docs {
+		if d.Path == filename {
+			doc, err := s.UpdateDocument(d.ID)
+			return doc, false, err
 		}
 	}
-	return nil
+
+	if _, err := os.Stat(filename); err != nil {
+		return nil, false, err
+	}
+	settings, err := s.Settings()
+	if err != nil {
+		return nil, false, err
+	}
+	idx, err := index.CreateInvertedIndex([]string{filename}, settings)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(idx.Docs) == 0 {
+		return nil, false, fmt.Errorf("can't index file %s", filename)
+	}
+	idx.SetRoot(root)
+
+	// the store renumbers the documents of the index, so the document gets its stored ID
+	for _, d := range idx.Docs {
+		doc = d
+	}
+	if err := s.UpdateIndex(*idx, &index.Changes{Added: []string{filename}}); err != nil {
+		return nil, false, err
+	}
+	return doc, true, nil
 }
 
-func (rep *IndexRepository) GetIndex(wordArr []string) (*index.Index, error) {
-	var ind = make(index.Index)
-	for _, v := range wordArr {
-		val, err := rep.c.Get(v).Result()
-		if err == redis.Nil {
-			log.Debug().Str("key", v).Msg("key does not exist")
-			return nil, err
-		} else if err != nil {
-			log.Err(err).Str("key", v).Msg("error while getting data by key")
-			return nil, err
-		} else {
-			var data []string
-			err := json.Unmarshal([]byte(val), &data)
-			if err != nil {
-				log.Err(err).Msg("error while db unmarshalling value")
-			}
-			ind[v] = data
+// missingTerms returns the words which aren't in the index
+func missingTerms(idx *index.Index, wordArr []string) []string {
+	var missing []string
+	for _, w := range wordArr {
+		if _, ok := idx.Terms[w]; !ok {
+			missing = append(missing, w)
 		}
 	}
-	return &ind, nil
+	return missing
+}
+
+func newStats(docs map[int]*index.Document, terms int) *Stats {
+	s := &Stats{
+		Documents: len(docs),
+		Terms:     terms,
+	}
+	for _, doc := range docs {
+		s.TotalLength += doc.Length
+	}
+	return s
 }
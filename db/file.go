@@ -0,0 +1,191 @@
+package db
+
+import (
+	"os"
+	"sync"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/polisgo2020/search-Arkronzxc/segment"
+	"github.com/rs/zerolog/log"
+)
+
+// FileStore keeps the index in the segment file. The file is mapped into memory for reading and is rewritten on every
+// modification
+type FileStore struct {
+	mu       sync.RWMutex
+	filename string
+	// seg is nil until the first index is saved
+	seg *segment.Segment
+}
+
+// NewFileStore opens the segment file, the file is created on the first save if it doesn't exist
+func NewFileStore(filename string) (*FileStore, error) {
+	f := &FileStore{
+		filename: filename,
+	}
+
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		log.Info().Str("filename", filename).Msg("segment file doesn't exist yet")
+		return f, nil
+	}
+
+	seg, err := segment.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	f.seg = seg
+	return f, nil
+}
+
+func (f *FileStore) SaveIndex(i index.Index) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.write(&i)
+}
+
+func (f *FileStore) UpdateIndex(i index.Index, changes *index.Changes) error {
+	return f.modify(func(idx *index.Index) error {
+		applyChanges(idx, &i, changes)
+		return nil
+	})
+}
+
+func (f *FileStore) GetIndex(wordArr []string, _ bool) (*index.Index, []string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return index.NewIndex(), wordArr, nil
+	}
+	ind, err := f.seg.GetIndex(wordArr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ind, missingTerms(ind, wordArr), nil
+}
+
+func (f *FileStore) GetDocuments() (map[int]*index.Document, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return make(map[int]*index.Document), nil
+	}
+	return f.seg.Documents(), nil
+}
+
+func (f *FileStore) RemoveDocument(id int) error {
+	return f.modify(func(idx *index.Index) error {
+		if _, ok := idx.Docs[id]; !ok {
+			return ErrNotFound
+		}
+		idx.RemoveDocument(id)
+		return nil
+	})
+}
+
+func (f *FileStore) UpdateDocument(id int) (*index.Document, error) {
+	var doc *index.Document
+	err := f.modify(func(idx *index.Index) error {
+		if err := idx.UpdateDocument(id); err != nil {
+			return err
+		}
+		doc = idx.Docs[id]
+		return nil
+	})
+	return doc, err
+}
+
+func (f *FileStore) Terms() ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return nil, nil
+	}
+	return append([]string{}, f.seg.Terms()...), nil
+}
+
+func (f *FileStore) Dictionary() (*index.Dictionary, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return index.NewDictionary(nil), nil
+	}
+	return f.seg.Dictionary(), nil
+}
+
+func (f *FileStore) Stats() (*Stats, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return &Stats{}, nil
+	}
+	return newStats(f.seg.Documents(), len(f.seg.Terms())), nil
+}
+
+func (f *FileStore) Settings() (analysis.Settings, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.seg == nil {
+		return analysis.DefaultSettings(), nil
+	}
+	return f.seg.Settings(), nil
+}
+
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.seg == nil {
+		return nil
+	}
+	err := f.seg.Close()
+	f.seg = nil
+	return err
+}
+
+// modify loads the whole index from the segment, applies the modification and rewrites the segment
+func (f *FileStore) modify(modification func(idx *index.Index) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := index.NewIndex()
+	if f.seg != nil {
+		loaded, err := f.seg.Load()
+		if err != nil {
+			return err
+		}
+		idx = loaded
+	}
+
+	if err := modification(idx); err != nil {
+		return err
+	}
+	return f.write(idx)
+}
+
+// write rewrites the segment file with the index and reopens it, the caller must hold the lock
+func (f *FileStore) write(idx *index.Index) error {
+	if err := segment.Write(f.filename, idx); err != nil {
+		log.Err(err).Str("filename", f.filename).Msg("error while writing segment")
+		return err
+	}
+
+	seg, err := segment.Open(f.filename)
+	if err != nil {
+		return err
+	}
+	if f.seg != nil {
+		if err := f.seg.Close(); err != nil {
+			log.Err(err).Str("filename", f.filename).Msg("error while closing previous segment")
+		}
+	}
+	f.seg = seg
+	return nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// MemoryStore keeps the index in memory, the index is lost when the process exits
+type MemoryStore struct {
+	mu  sync.RWMutex
+	idx *index.Index
+	// dict is the cached dictionary of the index, it's reset on every modification
+	dict *index.Dictionary
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		idx: index.NewIndex(),
+	}
+}
+
+func (m *MemoryStore) SaveIndex(i index.Index) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.idx = &i
+	m.dict = nil
+	return nil
+}
+
+func (m *MemoryStore) UpdateIndex(i index.Index, changes *index.Changes) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	applyChanges(m.idx, &i, changes)
+	m.dict = nil
+	return nil
+}
+
+func (m *MemoryStore) GetIndex(wordArr []string, _ bool) (*index.Index, []string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ind := index.NewIndex()
+	for _, w := range wordArr {
+		if postings, ok := m.idx.Terms[w]; ok {
+			// the search gets its own copy, so the updates don't change the postings it reads
+			ind.Terms[w] = append(index.Postings(nil), postings...)
+		}
+	}
+	ind.Docs = m.documents()
+	ind.NextID = m.idx.NextID
+	ind.Settings = m.idx.Settings
+	return ind, missingTerms(ind, wordArr), nil
+}
+
+func (m *MemoryStore) GetDocuments() (map[int]*index.Document, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.documents(), nil
+}
+
+func (m *MemoryStore) RemoveDocument(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.idx.Docs[id]; !ok {
+		return ErrNotFound
+	}
+	m.idx.RemoveDocument(id)
+	m.dict = nil
+	return nil
+}
+
+func (m *MemoryStore) UpdateDocument(id int) (*index.Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dict = nil
+	if err := m.idx.UpdateDocument(id); err != nil {
+		return nil, err
+	}
+	return m.idx.Docs[id], nil
+}
+
+func (m *MemoryStore) Terms() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := make([]string, 0, len(m.idx.Terms))
+	for t := range m.idx.Terms {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	return terms, nil
+}
+
+func (m *MemoryStore) Dictionary() (*index.Dictionary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dict == nil {
+		m.dict = m.idx.Dictionary()
+	}
+	return m.dict, nil
+}
+
+func (m *MemoryStore) Stats() (*Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return newStats(m.idx.Docs, len(m.idx.Terms)), nil
+}
+
+func (m *MemoryStore) Settings() (analysis.Settings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.idx.Settings, nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// documents returns the copy of the document table, so it can be used after the index is modified
+func (m *MemoryStore) documents() map[int]*index.Document {
+	docs := make(map[int]*index.Document, len(m.idx.Docs))
+	for id, doc := range m.idx.Docs {
+		docs[id] = doc
+	}
+	return docs
+}
+
+// applyChanges applies the changes to the index. The other index must contain the reindexed files of the changes
+func applyChanges(idx *index.Index, other *index.Index, changes *index.Changes) {
+	paths := idx.Paths()
+	for _, filename := range changes.Outdated() {
+		if doc, ok := paths[filename]; ok {
+			idx.RemoveDocument(doc.ID)
+		}
+	}
+	for _, doc := range changes.Touched {
+		idx.Docs[doc.ID] = doc
+	}
+	idx.Merge(other, paths)
+}
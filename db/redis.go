@@ -0,0 +1,915 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/config"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// aliasKey is the key with the namespace of the live index
+	aliasKey = "_current"
+	// versionKey is the key of the counter of the namespace versions
+	versionKey = "_version"
+
+	// termPrefix is the prefix of the keys with the postings of the terms. Terms may be any text depending on the
+	// analyzer, so they are kept apart from the service keys which start with '_'
+	termPrefix = "t:"
+	// docsKey is the key of the hash with the document table where field is the document ID
+	docsKey = "_docs"
+	// pathsKey is the key of the hash where field is the file path, value is the document ID
+	pathsKey = "_paths"
+	// nextIDKey is the key of the ID of the next added document
+	nextIDKey = "_next_id"
+	// settingsKey is the key of the JSON encoded analysis settings of the index
+	settingsKey = "_settings"
+	// docTermsPrefix is the prefix of the keys with the list of terms of every indexed document
+	docTermsPrefix = "_terms:"
+	// generationKey is the key of the counter of the in place updates of the namespace
+	generationKey = "_generation"
+	// dictKey is the key of the sorted set with all the terms of the index. All the terms have zero score, so the set
+	// is ordered lexicographically
+	dictKey = "_dict"
+	// docFreqsKey is the key of the hash where field is the term, value is its document frequency
+	docFreqsKey = "_df"
+	// surfacesKey is the key of the hash where field is the term, value is its surface form if it isn't the term itself
+	surfacesKey = "_surfaces"
+	// totalLengthKey is the key of the sum of the lengths of all the documents, it's used for the ranking when only
+	// the documents of the postings are read
+	totalLengthKey = "_total_length"
+)
+
+// RedisStore keeps the index in Redis: every term is a key with the term prefix and JSON encoded postings, the document
+// table and the service data are kept in the keys starting with underscore. All the keys of the index are in the
+// namespace, the alias key points to the namespace of the live index. SaveIndex writes the index into the new namespace
+// and switches the alias only when all the keys are written, so a failed build leaves the live index untouched.
+// UpdateIndex changes the live namespace in one transaction
+type RedisStore struct {
+	c         *redis.Client
+	batchSize int
+
+	// dict is the cached dictionary of the index at the namespace and generation of dictVersion
+	dictMu      sync.Mutex
+	dict        *index.Dictionary
+	dictVersion string
+}
+
+func NewRedisStore(conf *config.Config) (*RedisStore, error) {
+	cli := redis.NewClient(&redis.Options{
+		Addr:     conf.DbListen,
+		Password: "", // no password set
+		DB:       0,  // use default DB
+	})
+
+	pong, err := cli.Ping().Result()
+	if err != nil {
+		log.Err(err)
+		return nil, err
+	}
+	log.Info().Str("result", pong).Msg("connection successful")
+	return &RedisStore{
+		c:         cli,
+		batchSize: conf.RedisBatchSize,
+	}, nil
+}
+
+// key returns the key of the name in the namespace
+func key(ns, name string) string {
+	return ns + ":" + name
+}
+
+// termKey returns the key of the postings of the term in the namespace
+func termKey(ns, term string) string {
+	return key(ns, termPrefix+term)
+}
+
+// namespace returns the namespace of the live index or the empty string if there is no index yet
+func (rep *RedisStore) namespace() (string, error) {
+	ns, err := rep.c.Get(aliasKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		log.Err(err).Msg("error while getting index namespace")
+		return "", err
+	}
+	return ns, nil
+}
+
+// SaveIndex writes the index into the new namespace in batches and makes it live. The previous index is removed
+// after the switch
+func (rep *RedisStore) SaveIndex(i index.Index) error {
+	version, err := rep.c.Incr(versionKey).Result()
+	if err != nil {
+		log.Err(err).Msg("error while allocating index version")
+		return err
+	}
+	ns := "v" + strconv.FormatInt(version, 10)
+
+	if err := rep.writeIndex(ns, &i); err != nil {
+		log.Err(err).Str("namespace", ns).Msg("error while writing index, live index is kept")
+		if err := rep.removeNamespace(ns); err != nil {
+			log.Err(err).Str("namespace", ns).Msg("error while removing partially written index")
+		}
+		return err
+	}
+
+	previous, err := rep.c.GetSet(aliasKey, ns).Result()
+	if err != nil && err != redis.Nil {
+		log.Err(err).Str("namespace", ns).Msg("error while switching index namespace")
+		return err
+	}
+	log.Info().Str("namespace", ns).Int("terms", len(i.Terms)).Msg("index namespace switched")
+
+	if previous != "" {
+		if err := rep.removeNamespace(previous); err != nil {
+			log.Err(err).Str("namespace", previous).Msg("error while removing previous index")
+		}
+	}
+	return nil
+}
+
+// writeIndex writes all the keys of the index into the namespace
+func (rep *RedisStore) writeIndex(ns string, i *index.Index) error {
+	b := rep.newBatch()
+	for term, postings := range i.Terms {
+		finalJson, err := json.Marshal(postings)
+		if err != nil {
+			return err
+		}
+		k := termKey(ns, term)
+		member := &redis.Z{Member: term}
+		term, docFreq := term, len(postings)
+		surface, hasSurface := i.Surfaces[term]
+		if err := b.add(func(p redis.Pipeliner) {
+			p.Set(k, finalJson, 0)
+			p.ZAdd(key(ns, dictKey), member)
+			p.HSet(key(ns, docFreqsKey), term, docFreq)
+			if hasSurface {
+				p.HSet(key(ns, surfacesKey), term, surface)
+			}
+		}); err != nil {
+			log.Err(err).Str("key", term).Msg("error while setting values into DB")
+			return err
+		}
+	}
+	settings, err := json.Marshal(i.Settings)
+	if err != nil {
+		return err
+	}
+	totalLength := documentsLength(i.Docs)
+	if err := b.add(func(p redis.Pipeliner) {
+		p.Set(key(ns, nextIDKey), i.NextID, 0)
+		p.Set(key(ns, settingsKey), settings, 0)
+		p.Set(key(ns, totalLengthKey), totalLength, 0)
+	}); err != nil {
+		log.Err(err).Msg("error while setting next document ID and settings into DB")
+		return err
+	}
+	if err := rep.saveDocuments(b, ns, i, i.Docs); err != nil {
+		return err
+	}
+	return b.flush()
+}
+
+// removeNamespace removes all the keys of the namespace
+func (rep *RedisStore) removeNamespace(ns string) error {
+	b := rep.newBatch()
+	iter := rep.c.Scan(0, key(ns, "*"), int64(rep.batchSize)).Iterator()
+	for iter.Next() {
+		k := iter.Val()
+		if err := b.add(func(p redis.Pipeliner) {
+			p.Del(k)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return b.flush()
+}
+
+// UpdateIndex applies the changes to the live index in place. The index must contain the reindexed files of the
+// changes, changed files keep their stored document IDs and added files get new ones. All the writes are done in one
+// MULTI/EXEC transaction, so the searches see the whole update or none of it and a failed update leaves the index
+// untouched. The update fails if the index is saved or updated by another client at the same time
+func (rep *RedisStore) UpdateIndex(i index.Index, changes *index.Changes) error {
+
+	ns, err := rep.namespace()
+	if err != nil {
+		return err
+	}
+	if ns == "" {
+		log.Info().Msg("there is no index yet, the whole index is saved")
+		return rep.SaveIndex(i)
+	}
+
+	err = rep.c.Watch(func(tx *redis.Tx) error {
+		// the alias may be switched after it's read and before it's watched
+		if live, err := tx.Get(aliasKey).Result(); err != nil || live != ns {
+			return redis.TxFailedErr
+		}
+		return rep.updateIndex(tx, ns, &i, changes)
+	}, aliasKey, key(ns, generationKey))
+	if err == redis.TxFailedErr {
+		return fmt.Errorf("index was changed by another client during the update: %w", err)
+	}
+	if err != nil {
+		log.Err(err).Msg("error while updating index")
+	}
+	return err
+}
+
+// updateIndex reads the stored postings of the terms affected by the changes and writes the merged postings and the
+// documents in the transaction of the watched namespace
+func (rep *RedisStore) updateIndex(tx *redis.Tx, ns string, i *index.Index, changes *index.Changes) error {
+	previous, err := rep.getDocumentIDs(ns, append(changes.Reindexed(), changes.Removed...))
+	if err != nil {
+		return err
+	}
+
+	// the new documents get the IDs allocated at once and numbered in the order of Renumber
+	var added int64
+	for _, doc := range i.Docs {
+		if _, ok := previous[doc.Path]; !ok {
+			added++
+		}
+	}
+	var nextID int
+	if added > 0 {
+		last, err := rep.c.IncrBy(key(ns, nextIDKey), added).Result()
+		if err != nil {
+			log.Err(err).Msg("error while allocating document IDs")
+			return err
+		}
+		nextID = int(last - added)
+	}
+	i.Renumber(func(doc *index.Document) int {
+		if id, ok := previous[doc.Path]; ok {
+			return id
+		}
+		nextID++
+		return nextID - 1
+	})
+
+	outdated := make(map[int]struct{})
+	outdatedIDs := make([]int, 0, len(previous))
+	for _, filename := range changes.Outdated() {
+		if id, ok := previous[filename]; ok {
+			outdated[id] = struct{}{}
+			outdatedIDs = append(outdatedIDs, id)
+		}
+	}
+	lengthDelta, err := rep.lengthDelta(ns, i.Docs, outdatedIDs)
+	if err != nil {
+		return err
+	}
+
+	// the terms whose postings must be rewritten
+	affected := make(map[string]struct{}, len(i.Terms))
+	for term := range i.Terms {
+		affected[term] = struct{}{}
+	}
+	docTerms, err := rep.getDocumentTerms(ns, outdatedIDs)
+	if err != nil {
+		return err
+	}
+	for _, terms := range docTerms {
+		for _, term := range terms {
+			affected[term] = struct{}{}
+		}
+	}
+	terms := make([]string, 0, len(affected))
+	for term := range affected {
+		terms = append(terms, term)
+	}
+	stored, err := rep.getPostings(ns, terms)
+	if err != nil {
+		return err
+	}
+
+	b := transaction(tx.TxPipeline())
+	for _, term := range terms {
+		postings := i.Terms[term]
+		for _, p := range stored[term] {
+			if _, ok := outdated[p.DocID]; !ok {
+				postings = append(postings, p)
+			}
+		}
+		sort.Slice(postings, func(a, b int) bool {
+			return postings[a].DocID < postings[b].DocID
+		})
+
+		k := termKey(ns, term)
+		term := term
+		finalJson, err := json.Marshal(postings)
+		if err != nil {
+			return err
+		}
+		// the stored terms keep their surface forms, the new ones get the forms of the index
+		surface, newSurface := i.Surfaces[term]
+		newSurface = newSurface && len(stored[term]) == 0
+		if err := b.add(func(p redis.Pipeliner) {
+			if len(postings) == 0 {
+				p.Del(k)
+				p.ZRem(key(ns, dictKey), term)
+				p.HDel(key(ns, docFreqsKey), term)
+				p.HDel(key(ns, surfacesKey), term)
+			} else {
+				p.Set(k, finalJson, 0)
+				p.ZAdd(key(ns, dictKey), &redis.Z{Member: term})
+				p.HSet(key(ns, docFreqsKey), term, len(postings))
+				if newSurface {
+					p.HSet(key(ns, surfacesKey), term, surface)
+				}
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, filename := range changes.Removed {
+		id, ok := previous[filename]
+		if !ok {
+			continue
+		}
+		filename := filename
+		if err := b.add(func(p redis.Pipeliner) {
+			p.Del(key(ns, docTermsPrefix+strconv.Itoa(id)))
+			p.HDel(key(ns, docsKey), strconv.Itoa(id))
+			p.HDel(key(ns, pathsKey), filename)
+		}); err != nil {
+			return err
+		}
+	}
+
+	docs := make(map[int]*index.Document, len(i.Docs)+len(changes.Touched))
+	for id, doc := range i.Docs {
+		docs[id] = doc
+	}
+	for _, doc := range changes.Touched {
+		docs[doc.ID] = doc
+	}
+	if err := rep.saveDocuments(b, ns, i, docs); err != nil {
+		return err
+	}
+
+	if err := b.add(func(p redis.Pipeliner) {
+		p.IncrBy(key(ns, totalLengthKey), lengthDelta)
+		p.Incr(key(ns, generationKey))
+	}); err != nil {
+		return err
+	}
+	return b.flush()
+}
+
+// Terms returns the sorted stored terms from the term dictionary of the namespace
+func (rep *RedisStore) Terms() ([]string, error) {
+	ns, err := rep.namespace()
+	if err != nil || ns == "" {
+		return nil, err
+	}
+
+	terms, err := rep.c.ZRange(key(ns, dictKey), 0, -1).Result()
+	if err != nil {
+		log.Err(err).Msg("error while reading term dictionary")
+		return nil, err
+	}
+	return terms, nil
+}
+
+// Dictionary returns the dictionary of the stored terms. It's cached until the index is saved or updated, by this
+// process or any other
+func (rep *RedisStore) Dictionary() (*index.Dictionary, error) {
+	ns, err := rep.namespace()
+	if err != nil {
+		return nil, err
+	}
+	var generation string
+	if ns != "" {
+		generation, err = rep.c.Get(key(ns, generationKey)).Result()
+		if err != nil && err != redis.Nil {
+			log.Err(err).Msg("error while getting index generation")
+			return nil, err
+		}
+	}
+	version := ns + "@" + generation
+
+	rep.dictMu.Lock()
+	defer rep.dictMu.Unlock()
+
+	if rep.dict != nil && rep.dictVersion == version {
+		return rep.dict, nil
+	}
+	docFreqs, err := rep.docFreqs(ns)
+	if err != nil {
+		return nil, err
+	}
+	surfaces, err := rep.surfaces(ns)
+	if err != nil {
+		return nil, err
+	}
+	rep.dict = index.NewFreqDictionary(docFreqs)
+	rep.dict.SetSurfaces(surfaces)
+	rep.dictVersion = version
+	log.Debug().Str("version", version).Int("terms", rep.dict.Len()).Msg("term dictionary loaded")
+	return rep.dict, nil
+}
+
+// docFreqs returns the document frequencies of all the terms of the namespace
+func (rep *RedisStore) docFreqs(ns string) (map[string]int, error) {
+	docFreqs := make(map[string]int)
+	if ns == "" {
+		return docFreqs, nil
+	}
+
+	vals, err := rep.c.HGetAll(key(ns, docFreqsKey)).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting document frequencies")
+		return nil, err
+	}
+	for term, val := range vals {
+		docFreq, err := strconv.Atoi(val)
+		if err != nil {
+			log.Err(err).Str("term", term).Msg("error while parsing document frequency")
+			return nil, err
+		}
+		docFreqs[term] = docFreq
+	}
+	return docFreqs, nil
+}
+
+// surfaces returns the surface forms of the terms of the namespace which differ from the terms
+func (rep *RedisStore) surfaces(ns string) (map[string]string, error) {
+	if ns == "" {
+		return make(map[string]string), nil
+	}
+	surfaces, err := rep.c.HGetAll(key(ns, surfacesKey)).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting surface forms of terms")
+		return nil, err
+	}
+	return surfaces, nil
+}
+
+// Stats returns the statistics of the stored index
+func (rep *RedisStore) Stats() (*Stats, error) {
+	docs, err := rep.GetDocuments()
+	if err != nil {
+		return nil, err
+	}
+	terms, err := rep.Terms()
+	if err != nil {
+		return nil, err
+	}
+	return newStats(docs, len(terms)), nil
+}
+
+// Settings returns the analysis settings of the live index
+func (rep *RedisStore) Settings() (analysis.Settings, error) {
+	ns, err := rep.namespace()
+	if err != nil || ns == "" {
+		return analysis.DefaultSettings(), err
+	}
+	return rep.settings(ns)
+}
+
+// settings returns the analysis settings of the index in the namespace
+func (rep *RedisStore) settings(ns string) (analysis.Settings, error) {
+	val, err := rep.c.Get(key(ns, settingsKey)).Result()
+	if err == redis.Nil {
+		return analysis.DefaultSettings(), nil
+	} else if err != nil {
+		log.Err(err).Msg("error while getting index settings")
+		return analysis.Settings{}, err
+	}
+
+	var settings analysis.Settings
+	if err := json.Unmarshal([]byte(val), &settings); err != nil {
+		log.Err(err).Msg("error while db unmarshalling index settings")
+		return analysis.Settings{}, err
+	}
+	return settings, nil
+}
+
+// Close closes the connection to Redis
+func (rep *RedisStore) Close() error {
+	return rep.c.Close()
+}
+
+// RemoveDocument removes the document and its postings from the stored index
+func (rep *RedisStore) RemoveDocument(id int) error {
+	doc, err := rep.GetDocument(id)
+	if err != nil {
+		return err
+	}
+
+	return rep.UpdateIndex(*index.NewIndex(), &index.Changes{
+		Removed: []string{doc.Path},
+	})
+}
+
+// UpdateDocument reindexes the document keeping its ID
+func (rep *RedisStore) UpdateDocument(id int) (*index.Document, error) {
+	doc, err := rep.GetDocument(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(doc.Path); err != nil {
+		return nil, err
+	}
+
+	settings, err := rep.Settings()
+	if err != nil {
+		return nil, err
+	}
+	ind, err := index.CreateInvertedIndex([]string{doc.Path}, settings)
+	if err != nil {
+		return nil, err
+	}
+	if len(ind.Docs) == 0 {
+		return nil, fmt.Errorf("can't index file %s", doc.Path)
+	}
+	// the document keeps its ID when the index is renumbered by UpdateIndex
+	var updated *index.Document
+	for _, d := range ind.Docs {
+		d.RelPath = doc.RelPath
+		updated = d
+	}
+
+	if err := rep.UpdateIndex(*ind, &index.Changes{Changed: []string{doc.Path}}); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// GetIndex reads the postings of all the words with one MGET and the documents of the postings with one HMGET, the
+// whole document table is read only if allDocs is set. The words which aren't stored are returned as missing
+func (rep *RedisStore) GetIndex(wordArr []string, allDocs bool) (*index.Index, []string, error) {
+	var ind = index.NewIndex()
+	ns, err := rep.namespace()
+	if err != nil {
+		return nil, nil, err
+	} else if ns == "" {
+		return ind, wordArr, nil
+	}
+
+	postings, err := rep.getPostings(ns, wordArr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	for _, w := range wordArr {
+		if data, ok := postings[w]; ok {
+			ind.Terms[w] = data
+		} else {
+			missing = append(missing, w)
+		}
+	}
+
+	if allDocs {
+		ind.Docs, err = rep.getDocuments(ns)
+	} else {
+		err = rep.readPostingDocuments(ns, ind)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ind.Settings, err = rep.settings(ns); err != nil {
+		return nil, nil, err
+	}
+	return ind, missing, nil
+}
+
+// GetDocument returns the description of the indexed document
+func (rep *RedisStore) GetDocument(id int) (*index.Document, error) {
+	ns, err := rep.namespace()
+	if err != nil {
+		return nil, err
+	} else if ns == "" {
+		return nil, ErrNotFound
+	}
+
+	val, err := rep.c.HGet(key(ns, docsKey), strconv.Itoa(id)).Result()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Err(err).Int("id", id).Msg("error while getting document")
+		return nil, err
+	}
+
+	var doc index.Document
+	if err := json.Unmarshal([]byte(val), &doc); err != nil {
+		log.Err(err).Int("id", id).Msg("error while db unmarshalling document")
+		return nil, err
+	}
+	doc.FillMetadata()
+	return &doc, nil
+}
+
+// GetDocuments returns the document table of the index
+func (rep *RedisStore) GetDocuments() (map[int]*index.Document, error) {
+	ns, err := rep.namespace()
+	if err != nil {
+		return nil, err
+	} else if ns == "" {
+		return make(map[int]*index.Document), nil
+	}
+	return rep.getDocuments(ns)
+}
+
+// getDocuments returns the document table of the index in the namespace
+func (rep *RedisStore) getDocuments(ns string) (map[int]*index.Document, error) {
+	raw, err := rep.c.HGetAll(key(ns, docsKey)).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting documents")
+		return nil, err
+	}
+
+	docs := make(map[int]*index.Document, len(raw))
+	for k, v := range raw {
+		var doc index.Document
+		if err := json.Unmarshal([]byte(v), &doc); err != nil {
+			log.Err(err).Str("id", k).Msg("error while db unmarshalling document")
+			return nil, err
+		}
+		doc.FillMetadata()
+		docs[doc.ID] = &doc
+	}
+	return docs, nil
+}
+
+// readPostingDocuments reads the documents of the postings of the index and the statistics of all the documents into
+// the index
+func (rep *RedisStore) readPostingDocuments(ns string, ind *index.Index) error {
+	totalLength, err := rep.c.Get(key(ns, totalLengthKey)).Int()
+	if err != nil {
+		log.Err(err).Msg("error while getting total length of documents")
+		return err
+	}
+	docCount, err := rep.c.HLen(key(ns, docsKey)).Result()
+	if err != nil {
+		log.Err(err).Msg("error while counting documents")
+		return err
+	}
+
+	seen := make(map[int]struct{})
+	var ids []int
+	for _, postings := range ind.Terms {
+		for _, p := range postings {
+			if _, ok := seen[p.DocID]; !ok {
+				seen[p.DocID] = struct{}{}
+				ids = append(ids, p.DocID)
+			}
+		}
+	}
+	if ind.Docs, err = rep.getDocumentsByID(ns, ids); err != nil {
+		return err
+	}
+	ind.DocCount, ind.TotalLength = int(docCount), totalLength
+	return nil
+}
+
+// getDocumentsByID returns the documents of the IDs with one HMGET, the IDs which aren't stored are skipped
+func (rep *RedisStore) getDocumentsByID(ns string, ids []int) (map[int]*index.Document, error) {
+	docs := make(map[int]*index.Document, len(ids))
+	if len(ids) == 0 {
+		return docs, nil
+	}
+
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = strconv.Itoa(id)
+	}
+	values, err := rep.c.HMGet(key(ns, docsKey), fields...).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting documents")
+		return nil, err
+	}
+
+	for i, v := range values {
+		val, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var doc index.Document
+		if err := json.Unmarshal([]byte(val), &doc); err != nil {
+			log.Err(err).Str("id", fields[i]).Msg("error while db unmarshalling document")
+			return nil, err
+		}
+		doc.FillMetadata()
+		docs[doc.ID] = &doc
+	}
+	return docs, nil
+}
+
+// lengthDelta returns the change of the total length of the documents when the outdated documents are replaced by the
+// documents of the index
+func (rep *RedisStore) lengthDelta(ns string, docs map[int]*index.Document, outdated []int) (int64, error) {
+	old, err := rep.getDocumentsByID(ns, outdated)
+	if err != nil {
+		return 0, err
+	}
+	return int64(documentsLength(docs) - documentsLength(old)), nil
+}
+
+// documentsLength returns the sum of the lengths of the documents
+func documentsLength(docs map[int]*index.Document) int {
+	var ans int
+	for _, doc := range docs {
+		ans += doc.Length
+	}
+	return ans
+}
+
+// getDocumentIDs returns the map where key is the path of the indexed file, value is its document ID. Paths which
+// aren't indexed are skipped
+func (rep *RedisStore) getDocumentIDs(ns string, paths []string) (map[string]int, error) {
+	ans := make(map[string]int, len(paths))
+	if len(paths) == 0 {
+		return ans, nil
+	}
+
+	values, err := rep.c.HMGet(key(ns, pathsKey), paths...).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting document IDs")
+		return nil, err
+	}
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Err(err).Str("filename", paths[i]).Msg("error while parsing document ID")
+			return nil, err
+		}
+		ans[paths[i]] = id
+	}
+	return ans, nil
+}
+
+// saveDocuments queues the writes of the document table and the lists of the document terms taken from the index
+func (rep *RedisStore) saveDocuments(b *batch, ns string, i *index.Index, docs map[int]*index.Document) error {
+
+	docTerms := make(map[int][]string)
+	for term, postings := range i.Terms {
+		for _, p := range postings {
+			docTerms[p.DocID] = append(docTerms[p.DocID], term)
+		}
+	}
+
+	for id, doc := range docs {
+		finalJson, err := json.Marshal(doc)
+		if err != nil {
+			log.Err(err).Int("id", id).Msg("error while serializing document")
+			return err
+		}
+		field, path := strconv.Itoa(id), doc.Path
+		if err := b.add(func(p redis.Pipeliner) {
+			p.HSet(key(ns, docsKey), field, finalJson)
+			p.HSet(key(ns, pathsKey), path, field)
+		}); err != nil {
+			log.Err(err).Int("id", id).Msg("error while setting document into DB")
+			return err
+		}
+
+		// terms of the touched documents are not in the index and stay untouched
+		terms, ok := docTerms[id]
+		if !ok {
+			continue
+		}
+		sort.Strings(terms)
+		termsJson, err := json.Marshal(terms)
+		if err != nil {
+			log.Err(err).Int("id", id).Msg("error while serializing document terms")
+			return err
+		}
+		if err := b.add(func(p redis.Pipeliner) {
+			p.Set(key(ns, docTermsPrefix+field), termsJson, 0)
+		}); err != nil {
+			log.Err(err).Int("id", id).Msg("error while setting document terms into DB")
+			return err
+		}
+	}
+	return nil
+}
+
+// getPostings returns the stored postings of the terms with one MGET, the terms which aren't stored are skipped
+func (rep *RedisStore) getPostings(ns string, terms []string) (map[string]index.Postings, error) {
+	ans := make(map[string]index.Postings, len(terms))
+	if len(terms) == 0 {
+		return ans, nil
+	}
+
+	keys := make([]string, len(terms))
+	for i, term := range terms {
+		keys[i] = termKey(ns, term)
+	}
+	values, err := rep.c.MGet(keys...).Result()
+	if err != nil {
+		log.Err(err).Strs("keys", terms).Msg("error while getting data by keys")
+		return nil, err
+	}
+
+	for i, v := range values {
+		val, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var data index.Postings
+		if err := json.Unmarshal([]byte(val), &data); err != nil {
+			log.Err(err).Str("key", terms[i]).Msg("error while db unmarshalling value")
+			return nil, err
+		}
+		ans[terms[i]] = data
+	}
+	return ans, nil
+}
+
+// getDocumentTerms returns the stored lists of terms of the documents with one MGET
+func (rep *RedisStore) getDocumentTerms(ns string, ids []int) (map[int][]string, error) {
+	ans := make(map[int][]string, len(ids))
+	if len(ids) == 0 {
+		return ans, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = key(ns, docTermsPrefix+strconv.Itoa(id))
+	}
+	values, err := rep.c.MGet(keys...).Result()
+	if err != nil {
+		log.Err(err).Msg("error while getting document terms")
+		return nil, err
+	}
+
+	for i, v := range values {
+		val, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var terms []string
+		if err := json.Unmarshal([]byte(val), &terms); err != nil {
+			log.Err(err).Int("id", ids[i]).Msg("error while db unmarshalling document terms")
+			return nil, err
+		}
+		ans[ids[i]] = terms
+	}
+	return ans, nil
+}
+
+// batch queues the commands and executes them in MULTI/EXEC transactions of the batch size, all the commands are
+// executed in one transaction by flush if the size is zero
+type batch struct {
+	pipe   redis.Pipeliner
+	size   int
+	queued int
+}
+
+func (rep *RedisStore) newBatch() *batch {
+	size := rep.batchSize
+	if size <= 0 {
+		size = 1
+	}
+	return &batch{
+		pipe: rep.c.TxPipeline(),
+		size: size,
+	}
+}
+
+// transaction returns the batch which executes all the commands in one transaction of the pipeline
+func transaction(pipe redis.Pipeliner) *batch {
+	return &batch{pipe: pipe}
+}
+
+// add queues the commands and executes the batch if it's full
+func (b *batch) add(queue func(p redis.Pipeliner)) error {
+	queue(b.pipe)
+	b.queued++
+	if b.size == 0 || b.queued < b.size {
+		return nil
+	}
+	return b.flush()
+}
+
+// flush executes the queued commands
+func (b *batch) flush() error {
+	if b.queued == 0 {
+		return nil
+	}
+	b.queued = 0
+	_, err := b.pipe.Exec()
+	return err
+}
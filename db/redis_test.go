@@ -0,0 +1,243 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/config"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedisStore returns the store connected to the new in-memory Redis server which is stopped with the test
+func newTestRedisStore(t *testing.T, batchSize int) (*RedisStore, error) {
+	s, err := miniredis.Run()
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(s.Close)
+	return NewRedisStore(&config.Config{DbListen: s.Addr(), RedisBatchSize: batchSize})
+}
+
+// txHook counts the transactions and calls onTx before every transaction, the transaction fails if it returns the
+// error
+type txHook struct {
+	count int
+	onTx  func(count int) error
+}
+
+// failAt returns onTx of the hook which fails the transaction with the number
+func failAt(n int) func(int) error {
+	return func(count int) error {
+		if count == n {
+			return errTx
+		}
+		return nil
+	}
+}
+
+var errTx = errors.New("transaction failed")
+
+func (h *txHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *txHook) AfterProcess(context.Context, redis.Cmder) error {
+	return nil
+}
+
+func (h *txHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	h.count++
+	if h.onTx == nil {
+		return ctx, nil
+	}
+	return ctx, h.onTx(h.count)
+}
+
+func (h *txHook) AfterProcessPipeline(context.Context, []redis.Cmder) error {
+	return nil
+}
+
+// testIndex returns the index of the files with the contents written into the directory
+func testIndex(t *testing.T, dir string, contents ...string) *index.Index {
+	var files []string
+	for i, content := range contents {
+		filename := filepath.Join(dir, "file"+string(rune('1'+i)))
+		require.NoError(t, ioutil.WriteFile(filename, []byte(content), 0644))
+		files = append(files, filename)
+	}
+	idx, err := index.CreateInvertedIndex(files, analysis.DefaultSettings())
+	require.NoError(t, err)
+	return idx
+}
+
+// namespaceKeys returns the keys of the namespace
+func namespaceKeys(store *RedisStore, ns string) []string {
+	var ans []string
+	for _, k := range store.c.Keys(key(ns, "*")).Val() {
+		if strings.HasPrefix(k, ns+":") {
+			ans = append(ans, k)
+		}
+	}
+	return ans
+}
+
+func TestRedisSaveIndexSwitchesNamespace(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := newTestRedisStore(t, 2)
+	require.NoError(t, err)
+	defer store.Close()
+	hook := &txHook{}
+	store.c.AddHook(hook)
+
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "hello world", "golang world", "java")))
+	// the terms, the documents and the service keys don't fit into one transaction of the batch size
+	require.Greater(t, hook.count, 3)
+	ns, err := store.namespace()
+	require.NoError(t, err)
+	require.Equal(t, "v1", ns)
+
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "rust")))
+	ns, err = store.namespace()
+	require.NoError(t, err)
+	require.Equal(t, "v2", ns)
+	require.Empty(t, namespaceKeys(store, "v1"))
+
+	idx, missing, err := store.GetIndex([]string{"rust", "hello"}, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"hello"}, missing)
+	require.Len(t, idx.Docs, 1)
+}
+
+func TestRedisFailedSaveIndexKeepsLiveIndex(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := newTestRedisStore(t, 2)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "hello world", "golang world", "java")))
+
+	// the new index fails after some of its keys are written
+	store.c.AddHook(&txHook{onTx: failAt(3)})
+	err = store.SaveIndex(*testIndex(t, dir, "rust", "rust world", "python"))
+	require.True(t, errors.Is(err, errTx), err)
+
+	ns, err := store.namespace()
+	require.NoError(t, err)
+	require.Equal(t, "v1", ns)
+	require.Empty(t, namespaceKeys(store, "v2"))
+
+	idx, missing, err := store.GetIndex([]string{"hello", "world", "rust"}, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"rust"}, missing)
+	require.Len(t, idx.Terms["world"], 2)
+	require.Len(t, idx.Docs, 3)
+}
+
+// testUpdate returns the index of the changed first file and the changes of the files of testIndex
+func testUpdate(t *testing.T, store *RedisStore, dir string) (*index.Index, *index.Changes) {
+	changed := filepath.Join(dir, "file1")
+	require.NoError(t, ioutil.WriteFile(changed, []byte("hello rust"), 0644))
+	require.NoError(t, os.Chtimes(changed, time.Now(), time.Now().Add(time.Minute)))
+	added := filepath.Join(dir, "file4")
+	require.NoError(t, ioutil.WriteFile(added, []byte("python"), 0644))
+
+	docs, err := store.GetDocuments()
+	require.NoError(t, err)
+	changes, err := index.Diff(docs, []string{changed, filepath.Join(dir, "file2"), added})
+	require.NoError(t, err)
+	idx, err := index.CreateInvertedIndex(changes.Reindexed(), analysis.DefaultSettings())
+	require.NoError(t, err)
+	return idx, changes
+}
+
+func TestRedisUpdateIndexIsOneTransaction(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := newTestRedisStore(t, 2)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "hello world", "golang world", "java")))
+
+	hook := &txHook{}
+	store.c.AddHook(hook)
+	idx, changes := testUpdate(t, store, dir)
+	require.NoError(t, store.UpdateIndex(*idx, changes))
+	require.Equal(t, 1, hook.count)
+
+	actual, missing, err := store.GetIndex([]string{"hello", "world", "rust", "python", "java"}, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"java"}, missing)
+	require.Len(t, actual.Terms["world"], 1)
+	require.Equal(t, 3, actual.Terms["python"][0].DocID)
+	require.Len(t, actual.Docs, 3)
+}
+
+func TestRedisFailedUpdateIndexKeepsIndex(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := newTestRedisStore(t, 2)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "hello world", "golang world", "java")))
+	before, _, err := store.GetIndex([]string{"hello", "world", "java"}, true)
+	require.NoError(t, err)
+
+	store.c.AddHook(&txHook{onTx: failAt(1)})
+	idx, changes := testUpdate(t, store, dir)
+	require.True(t, errors.Is(store.UpdateIndex(*idx, changes), errTx))
+
+	after, missing, err := store.GetIndex([]string{"hello", "world", "java"}, true)
+	require.NoError(t, err)
+	require.Empty(t, missing)
+	require.Equal(t, before, after)
+}
+
+func TestRedisConcurrentUpdateIndexFails(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := newTestRedisStore(t, 2)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveIndex(*testIndex(t, dir, "hello world", "golang world", "java")))
+
+	// another client updates the index after the postings are read and before they are written
+	store.c.AddHook(&txHook{onTx: func(int) error {
+		return store.c.Incr(key("v1", generationKey)).Err()
+	}})
+	idx, changes := testUpdate(t, store, dir)
+	require.True(t, errors.Is(store.UpdateIndex(*idx, changes), redis.TxFailedErr))
+
+	actual, _, err := store.GetIndex([]string{"rust"}, true)
+	require.NoError(t, err)
+	require.Empty(t, actual.Terms)
+}
+
+func TestRedisTermKeys(t *testing.T) {
+	service := []string{aliasKey, versionKey, docsKey, pathsKey, nextIDKey, settingsKey, docTermsPrefix + "1",
+		generationKey, dictKey, docFreqsKey, totalLengthKey}
+	for _, name := range service {
+		// the whitespace and keyword analyzers keep any text as the term
+		require.NotEqual(t, key("v1", name), termKey("v1", name), name)
+	}
+}
@@ -0,0 +1,264 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type storeTestSuite struct {
+	suite.Suite
+	newStore func(t *testing.T, dir string) (Store, error)
+	dir      string
+	files    []string
+	store    Store
+}
+
+func TestMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, &storeTestSuite{newStore: func(*testing.T, string) (Store, error) {
+		return NewMemoryStore(), nil
+	}})
+}
+
+func TestFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, &storeTestSuite{newStore: func(_ *testing.T, dir string) (Store, error) {
+		return NewFileStore(filepath.Join(dir, "index.seg"))
+	}})
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, &storeTestSuite{newStore: func(t *testing.T, _ string) (Store, error) {
+		return newTestRedisStore(t, 2)
+	}})
+}
+
+func (f *storeTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(f.T(), err)
+	f.dir = dir
+
+	f.files = []string{f.write("file1", "hello world"), f.write("file2", "golang world"), f.write("file3", "java")}
+	f.store, err = f.newStore(f.T(), dir)
+	require.NoError(f.T(), err)
+
+	idx, err := index.CreateInvertedIndex(f.files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.NoError(f.T(), f.store.SaveIndex(*idx))
+}
+
+func (f *storeTestSuite) TearDownTest() {
+	require.NoError(f.T(), f.store.Close())
+	require.NoError(f.T(), os.RemoveAll(f.dir))
+}
+
+func (f *storeTestSuite) write(name, content string) string {
+	filename := filepath.Join(f.dir, name)
+	require.NoError(f.T(), ioutil.WriteFile(filename, []byte(content), 0644))
+	return filename
+}
+
+func (f *storeTestSuite) TestEmptyStore() {
+	dir := filepath.Join(f.dir, "empty")
+	require.NoError(f.T(), os.Mkdir(dir, 0755))
+	store, err := f.newStore(f.T(), dir)
+	require.NoError(f.T(), err)
+	defer store.Close()
+
+	idx, missing, err := store.GetIndex([]string{"hello"}, false)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"hello"}, missing)
+	require.Empty(f.T(), idx.Terms)
+	require.Empty(f.T(), idx.Docs)
+}
+
+func (f *storeTestSuite) TestGetIndex() {
+	idx, missing, err := f.store.GetIndex([]string{"world", "rust"}, true)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"rust"}, missing)
+	require.Equal(f.T(), index.Postings{
+		{DocID: 0, Freq: 1, Positions: []int{1}},
+		{DocID: 1, Freq: 1, Positions: []int{1}},
+	}, idx.Terms["world"])
+	require.NotContains(f.T(), idx.Terms, "rust")
+	require.Len(f.T(), idx.Docs, 3)
+
+	// the store may return only the documents of the postings, the ranking gets the statistics of all of them
+	idx, _, err = f.store.GetIndex([]string{"world"}, false)
+	require.NoError(f.T(), err)
+	require.Contains(f.T(), idx.Docs, 0)
+	require.Contains(f.T(), idx.Docs, 1)
+	if len(idx.Docs) < 3 {
+		require.Equal(f.T(), 3, idx.DocCount)
+		require.Equal(f.T(), 5, idx.TotalLength)
+	}
+}
+
+func (f *storeTestSuite) TestTermsAndStats() {
+	terms, err := f.store.Terms()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"golang", "hello", "java", "world"}, terms)
+
+	stats, err := f.store.Stats()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), &Stats{Documents: 3, Terms: 4, TotalLength: 5}, stats)
+}
+
+func (f *storeTestSuite) TestDictionary() {
+	dict, err := f.store.Dictionary()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []index.Match{{Term: "world", Distance: 1}}, dict.Fuzzy("wold", 1))
+	require.Equal(f.T(), 2, dict.DocFreq("world"))
+
+	require.NoError(f.T(), f.store.RemoveDocument(0))
+	dict, err = f.store.Dictionary()
+	require.NoError(f.T(), err)
+	require.False(f.T(), dict.Contains("hello"), "dictionary must be updated after the index is modified")
+}
+
+func (f *storeTestSuite) TestSurfaces() {
+	f.write("file3", "comparing compared comparing")
+	idx, err := index.CreateInvertedIndex(f.files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.NoError(f.T(), f.store.SaveIndex(*idx))
+
+	// the stored terms keep their surface forms, the new ones get the forms of the added file
+	added, _, err := AddDocument(f.store, f.write("file4", "Compare worlds Architectures"), f.dir)
+	require.NoError(f.T(), err)
+	dict, err := f.store.Dictionary()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), "comparing", dict.Surface("compar"))
+	require.Equal(f.T(), "world", dict.Surface("world"))
+	require.Equal(f.T(), "architectures", dict.Surface("architectur"))
+
+	require.NoError(f.T(), f.store.RemoveDocument(2))
+	require.NoError(f.T(), f.store.RemoveDocument(added.ID))
+	dict, err = f.store.Dictionary()
+	require.NoError(f.T(), err)
+	require.False(f.T(), dict.Contains("compar"))
+	require.Equal(f.T(), "compar", dict.Surface("compar"))
+}
+
+func (f *storeTestSuite) TestSettings() {
+	settings := analysis.Settings{Analyzer: analysis.Whitespace}
+	idx, err := index.CreateInvertedIndex(f.files, settings)
+	require.NoError(f.T(), err)
+	require.NoError(f.T(), f.store.SaveIndex(*idx))
+
+	actual, err := f.store.Settings()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), settings, actual)
+}
+
+func (f *storeTestSuite) TestUpdateIndex() {
+	docs, err := f.store.GetDocuments()
+	require.NoError(f.T(), err)
+
+	changed := f.write("file1", "hello golang architecture")
+	require.NoError(f.T(), os.Chtimes(changed, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(f.T(), os.Remove(f.files[1]))
+	added := f.write("file4", "world")
+	files := []string{changed, f.files[2], added}
+
+	changes, err := index.Diff(docs, files)
+	require.NoError(f.T(), err)
+	idx, err := index.CreateInvertedIndex(changes.Reindexed(), analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.NoError(f.T(), f.store.UpdateIndex(*idx, changes))
+
+	actual, _, err := f.store.GetIndex([]string{"hello", "golang", "architectur", "world", "java"}, false)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), 0, actual.Docs[0].ID)
+	require.Equal(f.T(), added, actual.Docs[3].Path)
+
+	expected, err := index.CreateInvertedIndex(files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), byPath(expected), byPath(actual))
+	f.requireCorpus()
+}
+
+// requireCorpus checks the statistics of all the documents returned with only a part of the document table
+func (f *storeTestSuite) requireCorpus() {
+	stats, err := f.store.Stats()
+	require.NoError(f.T(), err)
+	idx, _, err := f.store.GetIndex(nil, false)
+	require.NoError(f.T(), err)
+	if len(idx.Docs) < stats.Documents {
+		require.Equal(f.T(), stats.Documents, idx.DocCount)
+		require.Equal(f.T(), stats.TotalLength, idx.TotalLength)
+	}
+}
+
+func (f *storeTestSuite) TestRemoveDocument() {
+	require.NoError(f.T(), f.store.RemoveDocument(0))
+	require.Equal(f.T(), ErrNotFound, f.store.RemoveDocument(0))
+
+	idx, _, err := f.store.GetIndex([]string{"hello", "world"}, false)
+	require.NoError(f.T(), err)
+	require.NotContains(f.T(), idx.Terms, "hello")
+	require.Equal(f.T(), index.Postings{{DocID: 1, Freq: 1, Positions: []int{1}}}, idx.Terms["world"])
+	require.NotContains(f.T(), idx.Docs, 0)
+	f.requireCorpus()
+}
+
+func (f *storeTestSuite) TestUpdateDocument() {
+	f.write("file3", "java golang")
+	doc, err := f.store.UpdateDocument(2)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), 2, doc.ID)
+	require.Equal(f.T(), 2, doc.Length)
+
+	idx, _, err := f.store.GetIndex([]string{"golang"}, false)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), index.Postings{
+		{DocID: 1, Freq: 1, Positions: []int{0}},
+		{DocID: 2, Freq: 1, Positions: []int{1}},
+	}, idx.Terms["golang"])
+
+	_, err = f.store.UpdateDocument(5)
+	require.Equal(f.T(), ErrNotFound, err)
+}
+
+func (f *storeTestSuite) TestAddDocument() {
+	added := f.write("file4", "golang rocks")
+	doc, ok, err := AddDocument(f.store, added, f.dir)
+	require.NoError(f.T(), err)
+	require.True(f.T(), ok)
+	require.Equal(f.T(), 3, doc.ID)
+	require.Equal(f.T(), "file4", doc.RelPath)
+
+	idx, _, err := f.store.GetIndex([]string{"golang"}, false)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), added, idx.Docs[3].Path)
+	require.Len(f.T(), idx.Terms["golang"], 2)
+
+	// the indexed file is reindexed keeping its ID
+	f.write("file4", "java")
+	doc, ok, err = AddDocument(f.store, added, f.dir)
+	require.NoError(f.T(), err)
+	require.False(f.T(), ok)
+	require.Equal(f.T(), 3, doc.ID)
+
+	_, _, err = AddDocument(f.store, filepath.Join(f.dir, "missing"), f.dir)
+	require.True(f.T(), os.IsNotExist(err))
+}
+
+// byPath returns the index where documents are identified by their paths instead of IDs
+func byPath(idx *index.Index) map[string]map[string][]int {
+	ans := make(map[string]map[string][]int)
+	for _, doc := range idx.Docs {
+		ans[doc.Path] = map[string][]int{}
+	}
+	for term, postings := range idx.Terms {
+		for _, p := range postings {
+			ans[idx.Docs[p.DocID].Path][term] = p.Positions
+		}
+	}
+	return ans
+}
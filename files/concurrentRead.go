@@ -3,40 +3,64 @@ package files
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
-	"strings"
 	"sync"
-	"unicode"
-	"unicode/utf8"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+
 	"github.com/polisgo2020/search-Arkronzxc/util"
 )
 
-// ConcurrentReadFile concurrently read file and returns word array from file
-func ConcurrentReadFile(filename string) (wordArr []string, err error) {
+// chunk is a part of the word array read by a single goroutine
+type chunk struct {
+	number   int
+	words    []string
+	surfaces []string
+}
+
+// ConcurrentReadFile concurrently read file and returns word array from file analyzed by the analyzer. Words are returned
+// in the order they appear in the file, so the index of a word in the array is its position in the file. The words
+// dropped by the analyzer, such as the stop words, are left as empty strings to keep the positions of the rest. The
+// file is read at once if the analyzer needs the whole text
+func ConcurrentReadFile(filename string, analyzer analysis.Analyzer) (wordArr []string, err error) {
+	wordArr, _, err = ConcurrentReadFileSurfaces(filename, analyzer)
+	return wordArr, err
+}
+
+// ConcurrentReadFileSurfaces reads the word array of the file like ConcurrentReadFile does together with the surface
+// forms of the words, see analysis.AnalyzeSurfaces
+func ConcurrentReadFileSurfaces(filename string, analyzer analysis.Analyzer) (wordArr, surfaces []string, err error) {
+
+	if analysis.WholeText(analyzer) {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		return analysis.AnalyzeSurfaces(analyzer, string(data))
+	}
 
 	wg := sync.WaitGroup{}
 
 	file, err := os.Open(filename)
-	defer file.Close()
-
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer file.Close()
 
 	//chunkSize is 1 mb
 	const chunkSize = 1024 * 1024
-	goRoutineCount := int(math.Ceil(float64(util.FileSize(filename)/chunkSize))) + 1
+	fileSize := util.FileSize(filename)
+	goRoutineCount := int(math.Ceil(float64(fileSize)/chunkSize)) + 1
 
-
-	wordChannel := make(chan string, goRoutineCount)
+	chunkChannel := make(chan *chunk, goRoutineCount)
 
 	ctx, finish := context.WithCancel(context.Background())
+	defer finish()
 
 	errChannel := make(chan error, goRoutineCount)
 
@@ -50,75 +74,99 @@ func ConcurrentReadFile(filename string) (wordArr []string, err error) {
 	for i := 0; i < goRoutineCount; i++ {
 		wg.Add(1)
 		//start read goroutine which reads and handles curtain part of file
-		go read(ctx, &wg, current, limit, file, wordChannel, errChannel)
-		//point start of the next chunk by adding limit + 1 byte.
-		//Adding one byte will prevent the start of the next chunk exactly on the end of the previous chunk
-		current += limit + 1
+		go read(ctx, &wg, i, current, limit, file, analyzer, chunkChannel, errChannel)
+		//point start of the next chunk right after the end of the current one
+		current += limit
 	}
 
 	// starts goroutine which waits end of all the goroutines
-	go func(chW chan string, errChan chan error, wg *sync.WaitGroup) {
+	go func(chW chan *chunk, errChan chan error, wg *sync.WaitGroup) {
 		wg.Wait()
 		close(chW)
 		close(errChan)
-	}(wordChannel, errChannel, &wg)
+	}(chunkChannel, errChannel, &wg)
 
-	wordArr = make([]string, 0)
+	chunks := make([]*chunk, goRoutineCount)
 
-	// receiving values from either word channel or error channel until one of them won't close
+	// receiving values from either chunk channel or error channel until one of them won't close
 ReadLoop:
 	for {
 		select {
-		case data, ok := <-wordChannel:
+		case data, ok := <-chunkChannel:
 			// means the channel is already empty and closed
 			if !ok {
 				break ReadLoop
 			}
-			wordArr = append(wordArr, data)
+			chunks[data.number] = data
 
 		case errData, ok := <-errChannel:
 			if !ok {
 				break ReadLoop
 			}
 			// if some data came to err channel we send terminating signal to all other goroutines which got that context
-			finish()
-			return nil, errData
+			return nil, nil, errData
 		}
 	}
 
-	return wordArr, nil
-}
+	wordArr = make([]string, 0)
+	surfaces = make([]string, 0)
+	for _, c := range chunks {
+		// the chunks which start after the end of the file aren't sent
+		if c != nil {
+			wordArr = append(wordArr, c.words...)
+			surfaces = append(surfaces, c.surfaces...)
+		}
+	}
 
-// read writes in the word channel the words
-func read(ctx context.Context, wg *sync.WaitGroup, offset int64, limit int64, file *os.File,
-	wordChannel chan<- string, errChan chan<- error) {
+	return wordArr, surfaces, nil
+}
 
+// read sends to the chunk channel the words which start inside the [offset, offset+limit) byte range of the file
+func read(ctx context.Context, wg *sync.WaitGroup, number int, offset int64, limit int64, file *os.File,
+	analyzer analysis.Analyzer, chunkChannel chan<- *chunk, errChan chan<- error) {
 
 	defer wg.Done()
 
-	// shifts the pointer to the offset value.
-	_, _ = file.Seek(offset, 0)
-	reader := bufio.NewReader(file)
+	// every goroutine has its own reader over the file, so they don't share the file offset
+	start := offset
+	if offset != 0 {
+		// starts one byte earlier to find out whether the chunk begins in the middle of the word
+		start--
+	}
+	reader := bufio.NewReader(io.NewSectionReader(file, start, math.MaxInt64-start))
+
+	// size of read bytes counted from the offset
+	var cumulativeSize int64
 
 	// skips all the bytes before first space because they refer to the previous chunk if it's not the first chunk.
 	// If it is then starting to read from the start.
 	if offset != 0 {
-		_, err := reader.ReadBytes(' ')
+		prev, err := reader.ReadByte()
 		if err == io.EOF {
-			fmt.Println("EOF")
 			return
 		}
-
 		if err != nil {
-			log.Err(err)
+			log.Err(err).Msg("error while reading the chunk")
 			errChan <- err
 			return
 		}
-	}
 
+		if prev != ' ' {
+			b, err := reader.ReadBytes(' ')
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.Err(err).Msg("error while reading the chunk")
+				errChan <- err
+				return
+			}
+			cumulativeSize += int64(len(b))
+		}
+	}
 
-	// size of read bytes
-	var cumulativeSize int64
+	words := make([]string, 0)
+	surfaces := make([]string, 0)
 
 	// iterates over a space separated byte buffer. Another case is it terminates if context is done.
 	// It becomes done if some error occurred in any reading goroutine.
@@ -127,42 +175,37 @@ func read(ctx context.Context, wg *sync.WaitGroup, offset int64, limit int64, fi
 		case <-ctx.Done():
 			return
 		default:
-			if cumulativeSize > limit {
-				return
-			}
+		}
 
-			b, err := reader.ReadBytes(' ')
+		// the word which starts after the limit refers to the next chunk
+		if cumulativeSize >= limit {
+			break
+		}
 
-			if err == io.EOF {
-				return
-			}
+		b, err := reader.ReadBytes(' ')
+		if err != nil && err != io.EOF {
+			errChan <- err
+			return
+		}
 
-			if err != nil {
-				errChan <- err
-				return
-			}
+		cumulativeSize += int64(len(b))
 
-			cumulativeSize += int64(len(b))
-			s := string(b)
-			if s != "" {
-				t, _ := utf8.DecodeRune([]byte("'"))
-				f := func(c rune) bool {
-					return !unicode.IsLetter(c) && t != c
-				}
-				str := strings.FieldsFunc(s, f)
-
-				for i := range str {
-					w, err := util.CleanUserData(str[i])
-					if err != nil {
-						errChan <- err
-						return
-
-					}
-					if w != "" {
-						wordChannel <- w
-					}
-				}
-			}
+		terms, forms, analyzeErr := analysis.AnalyzeSurfaces(analyzer, string(b))
+		if analyzeErr != nil {
+			errChan <- analyzeErr
+			return
 		}
+		words = append(words, terms...)
+		surfaces = append(surfaces, forms...)
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	chunkChannel <- &chunk{
+		number:   number,
+		words:    words,
+		surfaces: surfaces,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/stretchr/testify/require"
 
 	"github.com/stretchr/testify/suite"
@@ -52,7 +53,7 @@ func (f *concurrencyTestSuite) TearDownSuite() {
 }
 
 func (f *concurrencyTestSuite) TestConcurrentReadFile() {
-	wordArr, _ := ConcurrentReadFile(f.file.Name())
+	wordArr, _ := ConcurrentReadFile(f.file.Name(), analysis.ForLanguage(analysis.English))
 	require.Equal(f.T(), f.expected, wordArr)
 }
 
@@ -61,9 +62,50 @@ func (f *concurrencyTestSuite) TestConcurrentReadFile2() {
 		require.Fail(f.T(), "can't write tmp file content")
 		return
 	}
+	// the stop word keeps its position
 	for i := 0; i < 10000; i++ {
-		f.expected = append(f.expected, "fill", "ice")
+		f.expected = append(f.expected, "fill", "", "ice")
 	}
-	wordArr, _ := ConcurrentReadFile(f.file.Name())
+	wordArr, _ := ConcurrentReadFile(f.file.Name(), analysis.ForLanguage(analysis.English))
 	require.Equal(f.T(), f.expected, wordArr)
 }
+
+func (f *concurrencyTestSuite) TestConcurrentReadFileSeveralChunks() {
+	file, err := ioutil.TempFile(".", "testFile")
+	if err != nil {
+		require.Fail(f.T(), fmt.Sprintf("can't create tmp file in current dir, error is %s", err))
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	// words of different length make chunk borders fall both inside the words and between them
+	var expected []string
+	for i := 0; i < 150000; i++ {
+		if _, err = file.WriteString("Hello world golang "); err != nil {
+			require.Fail(f.T(), "can't write tmp file content")
+			return
+		}
+		expected = append(expected, "hello", "world", "golang")
+	}
+
+	wordArr, err := ConcurrentReadFile(file.Name(), analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), expected, wordArr)
+}
+
+func (f *concurrencyTestSuite) TestConcurrentReadFileWholeText() {
+	file, err := ioutil.TempFile(".", "testFile")
+	require.NoError(f.T(), err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = file.WriteString("Hello  big\nworld \n")
+	require.NoError(f.T(), err)
+
+	keyword, err := analysis.Settings{Analyzer: analysis.Keyword}.New(analysis.English)
+	require.NoError(f.T(), err)
+	wordArr, err := ConcurrentReadFile(file.Name(), keyword)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"Hello big world"}, wordArr)
+}
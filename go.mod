@@ -3,6 +3,7 @@ module github.com/polisgo2020/search-Arkronzxc
 go 1.14
 
 require (
+	github.com/alicebob/miniredis/v2 v2.30.0
 	github.com/go-chi/chi v4.1.0+incompatible
 
 	github.com/go-redis/redis/v7 v7.2.0
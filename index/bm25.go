@@ -0,0 +1,97 @@
+package index
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultK1 is the default term frequency saturation parameter of BM25
+	DefaultK1 = 1.2
+	// DefaultB is the default document length normalization parameter of BM25
+	DefaultB = 0.75
+)
+
+// BM25 keeps the parameters of the Okapi BM25 ranking function
+type BM25 struct {
+	// K1 controls how quickly the score saturates with the growth of term frequency
+	K1 float64
+	// B controls how strongly the score is normalized by the document length
+	B float64
+}
+
+// Score returns the structure where the key is the document ID, and the value is the BM25 score of this document for
+// the cleaned search terms. Only documents containing at least one of the terms are returned
+func (m *Index) Score(terms []string, params BM25) map[int]float64 {
+	weights := make(map[string]float64, len(terms))
+	for _, t := range terms {
+		weights[t] += 1
+	}
+	return m.WeightedScore(weights, params)
+}
+
+// WeightedScore returns the BM25 scores of the documents like Score does, but the score of every term is multiplied
+// by its weight
+func (m *Index) WeightedScore(weights map[string]float64, params BM25) map[int]float64 {
+	return m.WeightedScoreOf(weights, params, nil)
+}
+
+// WeightedScoreOf returns the weighted scores like WeightedScore does only for the documents of the set, all the
+// documents are scored if the set is nil. The statistics of the terms are taken from the whole index, so the score of
+// the document doesn't depend on the set
+func (m *Index) WeightedScoreOf(weights map[string]float64, params BM25, docs map[int]struct{}) map[int]float64 {
+
+	ans := make(map[int]float64)
+
+	count, totalLength := m.corpus()
+	docCount := float64(count)
+	if docCount == 0 {
+		return ans
+	}
+	avgLength := float64(totalLength) / docCount
+
+	// terms are summed up in the same order to make the scores reproducible
+	terms := make([]string, 0, len(weights))
+	for t := range weights {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	for _, t := range terms {
+		postings, ok := m.Terms[t]
+		if !ok {
+			continue
+		}
+
+		docFreq := float64(len(postings))
+		idf := math.Log(1 + (docCount-docFreq+0.5)/(docFreq+0.5))
+
+		for _, p := range postings {
+			if docs != nil {
+				if _, ok := docs[p.DocID]; !ok {
+					continue
+				}
+			}
+			tf := float64(p.Freq)
+			norm := 1 - params.B
+			if d, ok := m.Docs[p.DocID]; ok && avgLength > 0 {
+				norm += params.B * float64(d.Length) / avgLength
+			}
+			ans[p.DocID] += weights[t] * idf * tf * (params.K1 + 1) / (tf + params.K1*norm)
+		}
+	}
+
+	return ans
+}
+
+// corpus returns the number of all the documents of the index and the sum of their lengths
+func (m *Index) corpus() (int, int) {
+	if m.DocCount > 0 {
+		return m.DocCount, m.TotalLength
+	}
+	var totalLength int
+	for _, d := range m.Docs {
+		totalLength += d.Length
+	}
+	return len(m.Docs), totalLength
+}
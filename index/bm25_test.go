@@ -0,0 +1,81 @@
+package index
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type bm25TestSuite struct {
+	suite.Suite
+	index  *Index
+	params BM25
+}
+
+func TestBM25TestSuite(t *testing.T) {
+	suite.Run(t, new(bm25TestSuite))
+}
+
+func (f *bm25TestSuite) SetupTest() {
+	f.params = BM25{K1: DefaultK1, B: DefaultB}
+	f.index = NewIndex()
+	f.index.Docs = map[int]*Document{
+		1: {ID: 1, Path: "file1", Length: 10},
+		2: {ID: 2, Path: "file2", Length: 10},
+		3: {ID: 3, Path: "file3", Length: 20},
+	}
+	f.index.Terms["golang"] = Postings{
+		{DocID: 1, Freq: 3, Positions: []int{0, 4, 8}},
+		{DocID: 2, Freq: 1, Positions: []int{2}},
+		{DocID: 3, Freq: 1, Positions: []int{5}},
+	}
+	f.index.Terms["java"] = Postings{
+		{DocID: 2, Freq: 1, Positions: []int{3}},
+	}
+}
+
+func (f *bm25TestSuite) TestScoreValue() {
+	actual := f.index.Score([]string{"java"}, f.params)
+	idf := math.Log(1 + (3-1+0.5)/(1+0.5))
+	norm := 1 - DefaultB + DefaultB*10/(40.0/3)
+	expected := idf * (DefaultK1 + 1) / (1 + DefaultK1*norm)
+	require.Len(f.T(), actual, 1)
+	require.InDelta(f.T(), expected, actual[2], 1e-9)
+}
+
+func (f *bm25TestSuite) TestTermFrequencyRanksHigher() {
+	actual := f.index.Score([]string{"golang"}, f.params)
+	require.Greater(f.T(), actual[1], actual[2])
+}
+
+func (f *bm25TestSuite) TestShortDocumentRanksHigher() {
+	actual := f.index.Score([]string{"golang"}, f.params)
+	require.Greater(f.T(), actual[2], actual[3])
+}
+
+func (f *bm25TestSuite) TestRareTermRanksHigher() {
+	actual := f.index.Score([]string{"golang", "java"}, f.params)
+	require.Greater(f.T(), actual[2], actual[1])
+}
+
+func (f *bm25TestSuite) TestUnknownTerm() {
+	actual := f.index.Score([]string{"rust"}, f.params)
+	require.Empty(f.T(), actual)
+}
+
+func (f *bm25TestSuite) TestWeightedScore() {
+	actual := f.index.Score([]string{"java"}, f.params)
+	weighted := f.index.WeightedScore(map[string]float64{"java": 0.5}, f.params)
+	require.InDelta(f.T(), actual[2]/2, weighted[2], 1e-9)
+}
+
+func (f *bm25TestSuite) TestScoreOfPartialDocuments() {
+	expected := f.index.Score([]string{"java"}, f.params)
+
+	// only the document of the postings is read from the store together with the statistics of all the documents
+	f.index.Docs = map[int]*Document{2: f.index.Docs[2]}
+	f.index.DocCount, f.index.TotalLength = 3, 40
+	require.Equal(f.T(), expected, f.index.Score([]string{"java"}, f.params))
+}
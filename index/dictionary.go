@@ -0,0 +1,275 @@
+package index
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxDistance is the maximum edit distance of the fuzzy lookups
+const MaxDistance = 2
+
+// Match is the term of the dictionary found by the fuzzy lookup
+type Match struct {
+	Term string
+	// Distance is the edit distance between the looked up word and the term
+	Distance int
+}
+
+// Dictionary is the sorted list of the index terms. Prefix, wildcard and range lookups are binary searches over the
+// sorted terms, fuzzy lookups go through the BK-tree over the terms, so only a small part of the terms is compared
+// with the looked up word
+type Dictionary struct {
+	terms []string
+	// docFreqs are the numbers of the documents containing the terms, they may be unknown
+	docFreqs map[string]int
+	// surfaces are the surface forms of the terms which differ from the terms
+	surfaces map[string]string
+	root     *bkNode
+}
+
+// bkNode is the node of the BK-tree, the key of the children is their edit distance to the term of the node
+type bkNode struct {
+	term     string
+	children map[int]*bkNode
+}
+
+// NewDictionary returns the dictionary of the terms with unknown document frequencies
+func NewDictionary(terms []string) *Dictionary {
+	d := &Dictionary{terms: append([]string{}, terms...)}
+	sort.Strings(d.terms)
+
+	for _, t := range d.terms {
+		d.insert(t)
+	}
+	return d
+}
+
+// NewFreqDictionary returns the dictionary of the terms where key is the term, value is its document frequency
+func NewFreqDictionary(docFreqs map[string]int) *Dictionary {
+	terms := make([]string, 0, len(docFreqs))
+	for t := range docFreqs {
+		terms = append(terms, t)
+	}
+	d := NewDictionary(terms)
+	d.docFreqs = docFreqs
+	return d
+}
+
+// Dictionary returns the dictionary of the index terms with their surface forms
+func (m *Index) Dictionary() *Dictionary {
+	docFreqs := make(map[string]int, len(m.Terms))
+	for t, postings := range m.Terms {
+		docFreqs[t] = len(postings)
+	}
+	d := NewFreqDictionary(docFreqs)
+
+	// the index may be changed after the dictionary is made
+	surfaces := make(map[string]string, len(m.Surfaces))
+	for t, surface := range m.Surfaces {
+		surfaces[t] = surface
+	}
+	d.SetSurfaces(surfaces)
+	return d
+}
+
+// SetSurfaces sets the surface forms of the terms where key is the term, value is its surface form. The terms which
+// aren't in the map are their own surface forms. The map must not be modified after that
+func (d *Dictionary) SetSurfaces(surfaces map[string]string) {
+	d.surfaces = surfaces
+}
+
+func (d *Dictionary) insert(term string) {
+	if d.root == nil {
+		d.root = &bkNode{term: term}
+		return
+	}
+
+	n := d.root
+	for {
+		dist := Distance(term, n.term)
+		if dist == 0 {
+			return
+		}
+		child, ok := n.children[dist]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[int]*bkNode)
+			}
+			n.children[dist] = &bkNode{term: term}
+			return
+		}
+		n = child
+	}
+}
+
+// Len returns the number of the terms
+func (d *Dictionary) Len() int {
+	return len(d.terms)
+}
+
+// Terms returns the sorted terms, the slice must not be modified
+func (d *Dictionary) Terms() []string {
+	return d.terms
+}
+
+// DocFreq returns the number of the documents containing the term or 0 if it's unknown
+func (d *Dictionary) DocFreq(term string) int {
+	return d.docFreqs[term]
+}
+
+// Surface returns the surface form of the term, it's shown to the users instead of the term
+func (d *Dictionary) Surface(term string) string {
+	if surface, ok := d.surfaces[term]; ok {
+		return surface
+	}
+	return term
+}
+
+// Contains reports whether the term is in the dictionary
+func (d *Dictionary) Contains(term string) bool {
+	i := sort.SearchStrings(d.terms, term)
+	return i < len(d.terms) && d.terms[i] == term
+}
+
+// Prefix returns the sorted terms starting with the prefix
+func (d *Dictionary) Prefix(prefix string) []string {
+	from := sort.SearchStrings(d.terms, prefix)
+	to := from
+	for to < len(d.terms) && strings.HasPrefix(d.terms[to], prefix) {
+		to++
+	}
+	return d.terms[from:to:to]
+}
+
+// Wildcard returns the sorted terms matching the pattern where '*' matches any number of letters and '?' matches
+// exactly one letter. Only the terms with the literal prefix of the pattern are compared with it
+func (d *Dictionary) Wildcard(pattern string) []string {
+	prefix := pattern
+	if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	var ans []string
+	for _, t := range d.Prefix(prefix) {
+		if matchWildcard([]rune(pattern), []rune(t)) {
+			ans = append(ans, t)
+		}
+	}
+	return ans
+}
+
+// Range returns the sorted terms between from and to, the empty bound means the range is open on that side
+func (d *Dictionary) Range(from, to string, includeFrom, includeTo bool) []string {
+	start := 0
+	if from != "" {
+		start = sort.SearchStrings(d.terms, from)
+		if !includeFrom && start < len(d.terms) && d.terms[start] == from {
+			start++
+		}
+	}
+	end := len(d.terms)
+	if to != "" {
+		end = sort.SearchStrings(d.terms, to)
+		if includeTo && end < len(d.terms) && d.terms[end] == to {
+			end++
+		}
+	}
+	if start >= end {
+		return nil
+	}
+	return d.terms[start:end:end]
+}
+
+// matchWildcard reports whether the term matches the pattern. The last '*' is backtracked to, so the time is linear
+// in the product of the lengths at worst
+func matchWildcard(pattern, term []rune) bool {
+	p, t := 0, 0
+	star, starTerm := -1, 0
+	for t < len(term) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == term[t]):
+			p++
+			t++
+		case p < len(pattern) && pattern[p] == '*':
+			star, starTerm = p, t
+			p++
+		case star >= 0:
+			starTerm++
+			p, t = star+1, starTerm
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// Fuzzy returns the terms within the edit distance of the word ordered by the distance and then by the term
+func (d *Dictionary) Fuzzy(word string, distance int) []Match {
+	var ans []Match
+	if d.root == nil {
+		return ans
+	}
+
+	stack := []*bkNode{d.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		dist := Distance(word, n.term)
+		if dist <= distance {
+			ans = append(ans, Match{Term: n.term, Distance: dist})
+		}
+		// by the triangle inequality only the children at distance dist±distance can contain the matches
+		for childDist, child := range n.children {
+			if childDist >= dist-distance && childDist <= dist+distance {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Distance != ans[j].Distance {
+			return ans[i].Distance < ans[j].Distance
+		}
+		return ans[i].Term < ans[j].Term
+	})
+	return ans
+}
+
+// Distance returns the Levenshtein distance between the words: the number of inserted, deleted or substituted letters
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	// only the previous row of the matrix is kept
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			next := min(min(row[j]+1, row[j-1]+1), diag+cost)
+			diag = row[j]
+			row[j] = next
+		}
+	}
+	return row[len(rb)]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "golang", b: "golang", want: 0},
+		{a: "golang", b: "", want: 6},
+		{a: "golang", b: "golnag", want: 2},
+		{a: "golang", b: "gopang", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "книга", b: "книги", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); got != tt.want {
+				t.Errorf("Distance() = %v, want %v", got, tt.want)
+			}
+			if got := Distance(tt.b, tt.a); got != tt.want {
+				t.Errorf("Distance() of swapped words = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionaryFuzzy(t *testing.T) {
+	terms := []string{"golang", "google", "go", "goal", "java", "javascript", "world", "word", "work", "sword"}
+	d := NewDictionary(terms)
+
+	require.True(t, d.Contains("java"))
+	require.False(t, d.Contains("jav"))
+	require.Equal(t, []Match{{Term: "golang", Distance: 1}}, d.Fuzzy("gopang", 1))
+	require.Equal(t, []Match{{Term: "word", Distance: 0}, {Term: "sword", Distance: 1}, {Term: "work", Distance: 1},
+		{Term: "world", Distance: 1}}, d.Fuzzy("word", 1))
+
+	// the tree must find the same terms as the comparison with every term
+	for _, word := range []string{"gol", "wrd", "javascrpt", "xyz", ""} {
+		for distance := 0; distance <= MaxDistance; distance++ {
+			var want []Match
+			for _, m := range NewDictionary(terms).Terms() {
+				if dist := Distance(word, m); dist <= distance {
+					want = append(want, Match{Term: m, Distance: dist})
+				}
+			}
+			require.ElementsMatch(t, want, d.Fuzzy(word, distance), "word %q, distance %d", word, distance)
+		}
+	}
+}
+
+func TestDictionaryEnumeration(t *testing.T) {
+	d := NewDictionary([]string{"code", "coda", "cone", "cod", "codex", "core", "optim", "optimum", "option"})
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{name: "Prefix", got: d.Prefix("optim"), want: []string{"optim", "optimum"}},
+		{name: "Unknown prefix", got: d.Prefix("x"), want: []string{}},
+		{name: "Prefix wildcard", got: d.Wildcard("opt*"), want: []string{"optim", "optimum", "option"}},
+		{name: "Single letter wildcard", got: d.Wildcard("co?e"), want: []string{"code", "cone", "core"}},
+		{name: "Leading wildcard", got: d.Wildcard("*de*"), want: []string{"code", "codex"}},
+		{name: "Wildcard without wildcards", got: d.Wildcard("cod"), want: []string{"cod"}},
+		{name: "Inclusive range", got: d.Range("cod", "code", true, true), want: []string{"cod", "coda", "code"}},
+		{name: "Exclusive range", got: d.Range("cod", "code", false, false), want: []string{"coda"}},
+		{name: "Open range", got: d.Range("option", "", true, false), want: []string{"option"}},
+		{name: "Empty range", got: d.Range("z", "a", true, true), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.got)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package index
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+const (
+	// maxTitleLength is the maximum number of characters in the document title
+	maxTitleLength = 80
+	// sampleSize is the number of bytes from the beginning of the file used to detect its language
+	sampleSize = 4096
+)
+
+// Document describes the indexed version of a file
+type Document struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+	// RelPath is the slash separated path of the file relative to the indexed directory
+	RelPath string `json:"relPath"`
+	// Ext is the lowercase extension of the file without the dot
+	Ext string `json:"ext"`
+	// Title is the first non-empty line of the file or the file name if the file has no such line
+	Title string `json:"title"`
+	// Length is the number of words in the file
+	Length  int       `json:"length"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	// Hash is the hex encoded SHA-256 of the file content
+	Hash string `json:"hash"`
+	// Language is the language of the file, it is detected unless the index settings fix the language
+	Language analysis.Language `json:"language"`
+}
+
+// NewDocument returns the description of the file on disk without the document ID and the number of words in it
+func NewDocument(filename string) (*Document, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	reader := bufio.NewReaderSize(io.TeeReader(file, h), sampleSize)
+
+	sample, err := reader.Peek(sampleSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	lang := analysis.Detect(string(sample))
+
+	title, err := readTitle(reader)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		title = filepath.Base(filename)
+	}
+
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Path:     filename,
+		RelPath:  filepath.ToSlash(filepath.Clean(filename)),
+		Ext:      Extension(filename),
+		Title:    title,
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Hash:     hex.EncodeToString(h.Sum(nil)),
+		Language: lang,
+	}, nil
+}
+
+// Extension returns the lowercase extension of the file name without the dot
+func Extension(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// SetRoot makes the relative path of the document relative to the root directory. The path is kept as it is if the
+// file isn't in the directory
+func (d *Document) SetRoot(root string) {
+	rel, err := filepath.Rel(root, d.Path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return
+	}
+	if rel == "." {
+		// the root is the file itself
+		rel = filepath.Base(d.Path)
+	}
+	d.RelPath = filepath.ToSlash(rel)
+}
+
+// FillMetadata sets the relative path and the extension of the document indexed before they were captured
+func (d *Document) FillMetadata() {
+	if d.RelPath == "" {
+		d.RelPath = filepath.ToSlash(filepath.Clean(d.Path))
+	}
+	if d.Ext == "" {
+		d.Ext = Extension(d.Path)
+	}
+}
+
+// readTitle returns the first non-empty line of the reader cut to the maximum title length
+func readTitle(reader *bufio.Reader) (string, error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+
+		if title := strings.TrimSpace(line); title != "" {
+			if runes := []rune(title); len(runes) > maxTitleLength {
+				title = string(runes[:maxTitleLength])
+			}
+			return title, nil
+		}
+
+		if err == io.EOF {
+			return "", nil
+		}
+	}
+}
+
+// SetRoot makes the relative paths of the documents relative to the root directory
+func (m *Index) SetRoot(root string) {
+	for _, doc := range m.Docs {
+		doc.SetRoot(root)
+	}
+}
+
+// Paths returns the map where key is the file path, value is the document of the file
+func (m *Index) Paths() map[string]*Document {
+	ans := make(map[string]*Document, len(m.Docs))
+	for _, doc := range m.Docs {
+		ans[doc.Path] = doc
+	}
+	return ans
+}
+
+// Renumber changes the document IDs of the index to the ones returned by newID. Documents are passed to newID in the
+// order of their current IDs
+func (m *Index) Renumber(newID func(doc *Document) int) {
+
+	ids := make([]int, 0, len(m.Docs))
+	for id := range m.Docs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	mapping := make(map[int]int, len(ids))
+	docs := make(map[int]*Document, len(ids))
+	for _, id := range ids {
+		doc := m.Docs[id]
+		doc.ID = newID(doc)
+		mapping[id] = doc.ID
+		docs[doc.ID] = doc
+		if doc.ID >= m.NextID {
+			m.NextID = doc.ID + 1
+		}
+	}
+	m.Docs = docs
+
+	for _, postings := range m.Terms {
+		for _, p := range postings {
+			p.DocID = mapping[p.DocID]
+		}
+		postings.sort()
+	}
+}
@@ -1,98 +1,246 @@
 package index
 
 import (
-
+	"sort"
+	"strings"
 	"sync"
 
-	"github.com/polisgo2020/search-Arkronzxc/util"
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/rs/zerolog/log"
 
 	"github.com/polisgo2020/search-Arkronzxc/files"
 )
 
-type Index map[string][]string
+// Posting describes the occurrences of a term in a single document
+type Posting struct {
+	DocID int `json:"doc"`
+	// Freq is the number of times the term occurs in the document
+	Freq int `json:"freq"`
+	// Positions are the ordinal numbers of the term among all the words of the document
+	Positions []int `json:"positions"`
+}
 
-// CreateInvertedIndex returns map where key is a word in file, value is filename
-func CreateInvertedIndex(files []string) (*Index, error) {
+// Postings is the list of the term occurrences sorted by document ID
+type Postings []*Posting
+
+// Index is the inverted index of the files
+type Index struct {
+	// Terms is the map where key is a word, value is the postings of the documents containing this word
+	Terms map[string]Postings `json:"terms"`
+	// Docs is the document table where key is the document ID
+	Docs map[int]*Document `json:"docs"`
+	// NextID is the ID of the next added document
+	NextID int `json:"nextId"`
+	// Settings describe the analyzer of the documents and the queries
+	Settings analysis.Settings `json:"settings"`
+	// Surfaces is the map where key is a term, value is its most frequent surface form in the documents, it's shown
+	// to the users instead of the term. The terms whose surface form is the term itself aren't in the map
+	Surfaces map[string]string `json:"surfaces,omitempty"`
+	// DocCount and TotalLength are the number of all the documents and the sum of their lengths. The store sets them
+	// when Docs is only a part of the document table, otherwise they are zero and are counted from Docs
+	DocCount    int `json:"-"`
+	TotalLength int `json:"-"`
+}
+
+// FileMap keeps the postings of every word of a single file
+type FileMap struct {
+	Document *Document
+	Postings map[string]*Posting
+	// Surfaces is the map where key is a word, value is the number of times every surface form of the word occurs
+	Surfaces map[string]map[string]int
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		Terms:    make(map[string]Postings),
+		Docs:     make(map[int]*Document),
+		Settings: analysis.DefaultSettings(),
+		Surfaces: make(map[string]string),
+	}
+}
+
+// CreateInvertedIndex returns index where key is a word in file, value is the postings of the word. The files get
+// document IDs in the order they are passed and are analyzed with the analyzer of the settings
+func CreateInvertedIndex(files []string, settings analysis.Settings) (*Index, error) {
 
 	log.Debug().Strs("files", files).Msg("files to index: ")
 
-	m := make(Index)
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := NewIndex()
+	m.Settings = settings
 
 	wg := sync.WaitGroup{}
-	fileChan := make(chan map[string]string, 1000)
+	fileChan := make(chan *FileMap, 1000)
 
+	// documents are numbered in the order of the files
 	for i := range files {
 		wg.Add(1)
-		go ConcurrentBuildFileMap(&wg, files[i], fileChan)
+		go ConcurrentBuildFileMap(&wg, i, files[i], settings, fileChan)
 	}
+	m.NextID = len(files)
 
-	go func(wg *sync.WaitGroup, readChan chan map[string]string) {
+	go func(wg *sync.WaitGroup, readChan chan *FileMap) {
 		wg.Wait()
 		close(readChan)
 	}(&wg, fileChan)
 
+	surfaces := make(map[string]map[string]int)
 	for data := range fileChan {
-		for j := range data {
-			if m[j] == nil {
-				m[j] = []string{data[j]}
-			} else {
-				m[j] = append(m[j], data[j])
+		m.Docs[data.Document.ID] = data.Document
+		for j := range data.Postings {
+			m.Terms[j] = append(m.Terms[j], data.Postings[j])
+		}
+		for term, counts := range data.Surfaces {
+			if surfaces[term] == nil {
+				surfaces[term] = make(map[string]int, len(counts))
+			}
+			for surface, count := range counts {
+				surfaces[term][surface] += count
 			}
 		}
 	}
+	for term, counts := range surfaces {
+		m.setSurface(term, mostFrequent(counts))
+	}
+
+	// files are indexed concurrently so postings must be ordered to make the index deterministic
+	for j := range m.Terms {
+		m.Terms[j].sort()
+	}
 
 	log.Debug().Msg("inverted index created")
 
-	return &m, nil
+	return m, nil
 }
 
-// ConcurrentBuildFileMap concurrently writes words into the word array and iterates over it applying filename as value
-func ConcurrentBuildFileMap(wg *sync.WaitGroup, filename string, mapChan chan<- map[string]string) {
+// ConcurrentBuildFileMap concurrently reads the word array of the file and sends the posting of every word in it. The
+// file gets the passed document ID
+func ConcurrentBuildFileMap(wg *sync.WaitGroup, id int, filename string, settings analysis.Settings,
+	mapChan chan<- *FileMap) {
 
 	defer wg.Done()
 
-	m := map[string]string{}
-	wordArr, err := files.ConcurrentReadFile(filename)
+	m := map[string]*Posting{}
+	doc, err := NewDocument(filename)
+	if err != nil {
+		log.Err(err).Str("filename", filename).Msg("error while reading file state")
+		return
+	}
+	if settings.Language != "" {
+		doc.Language = settings.Language
+	}
+	analyzer, err := settings.New(doc.Language)
+	if err != nil {
+		log.Err(err).Str("filename", filename).Msg("error while creating analyzer")
+		return
+	}
+	wordArr, surfaces, err := files.ConcurrentReadFileSurfaces(filename, analyzer)
 	if err != nil {
 		log.Err(err).Msg("error while reading file concurrently")
 		return
 	}
+	doc.ID = id
 
+	counts := make(map[string]map[string]int)
+	// the dropped words keep their positions but aren't indexed
 	for i := range wordArr {
-		m[wordArr[i]] = filename
+		if wordArr[i] == "" {
+			continue
+		}
+		doc.Length++
+		p, ok := m[wordArr[i]]
+		if !ok {
+			p = &Posting{DocID: id}
+			m[wordArr[i]] = p
+		}
+		p.Freq++
+		p.Positions = append(p.Positions, i)
+
+		if counts[wordArr[i]] == nil {
+			counts[wordArr[i]] = make(map[string]int)
+		}
+		counts[wordArr[i]][surfaces[i]]++
+	}
+
+	mapChan <- &FileMap{
+		Document: doc,
+		Postings: m,
+		Surfaces: counts,
+	}
+}
+
+// Surface returns the surface form of the term
+func (m *Index) Surface(term string) string {
+	if surface, ok := m.Surfaces[term]; ok {
+		return surface
+	}
+	return term
+}
+
+// setSurface sets the surface form of the term, only the forms which differ from the term are kept
+func (m *Index) setSurface(term, surface string) {
+	if surface == "" || surface == term {
+		delete(m.Surfaces, term)
+		return
+	}
+	if m.Surfaces == nil {
+		m.Surfaces = make(map[string]string)
+	}
+	m.Surfaces[term] = surface
+}
+
+// mostFrequent returns the surface form with the highest count, the first one in the alphabetical order of the forms
+// with equal counts
+func mostFrequent(counts map[string]int) string {
+	var ans string
+	for surface, count := range counts {
+		if count > counts[ans] || count == counts[ans] && (ans == "" || surface < ans) {
+			ans = surface
+		}
 	}
+	return ans
+}
 
-	mapChan <- m
+// sort orders the postings by document ID
+func (p Postings) sort() {
+	sort.Slice(p, func(a, b int) bool {
+		return p[a].DocID < p[b].DocID
+	})
 }
 
 // BuildSearchIndex searches by index and returns the structure where the key is the file name, and the value is the
-// number of words from the search query that were found in this file
+// number of words from the search query that were found in this file. The words are analyzed by the index analyzer
 func (m *Index) BuildSearchIndex(searchArgs []string) (map[string]int, error) {
 
-
 	ans := make(map[string]int)
 
-	var cleanData []string
-	for i := range searchArgs {
-		w, err := util.CleanUserData(searchArgs[i])
-		if err != nil {
-			return nil, err
-		}
-		if w != "" {
-			cleanData = append(cleanData, w)
-		}
+	text := strings.Join(searchArgs, " ")
+	lang, err := m.Settings.LanguageOf("", text)
+	if err != nil {
+		return nil, err
+	}
+	analyzer, err := m.Settings.New(lang)
+	if err != nil {
+		return nil, err
+	}
+	cleanData, err := analyzer.Analyze(text)
+	if err != nil {
+		return nil, err
 	}
-
 
 	for _, v := range cleanData {
-		if filesArray, ok := (*m)[v]; ok {
-			for _, fileName := range filesArray {
-				ans[fileName]++
+		if postings, ok := m.Terms[v]; ok {
+			for _, p := range postings {
+				if doc, ok := m.Docs[p.DocID]; ok {
+					ans[doc.Path]++
+				}
 			}
 		}
 	}
 
 	return ans, nil
 }
-
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -26,14 +27,17 @@ func TestSearchTestSuite(t *testing.T) {
 }
 
 func (f *searchTestSuite) SetupTest() {
-	f.index = make(Index)
+	f.index = *NewIndex()
+	for i, filename := range []string{"file1", "file2", "file3", "file4"} {
+		f.index.Docs[i+1] = &Document{ID: i + 1, Path: filename, Length: 1}
+	}
 	f.firstExpectedCase = make(map[string]int)
 	f.secondExpectedCase = make(map[string]int)
-	f.index["hello"] = []string{"file1", "file2"}
-	f.index["world"] = []string{"file1", "file4"}
-	f.index["golang"] = []string{"file2", "file3", "file4"}
-	f.index["java"] = []string{"file1"}
-	f.index["architectur"] = []string{"file1"}
+	f.index.Terms["hello"] = postings(1, 2)
+	f.index.Terms["world"] = postings(1, 4)
+	f.index.Terms["golang"] = postings(2, 3, 4)
+	f.index.Terms["java"] = postings(1)
+	f.index.Terms["architectur"] = postings(1)
 	f.firstSearchQuery = []string{"hello", "world"}
 	f.secondSearchQuery = []string{"golang", "java"}
 	f.firstExpectedCase = map[string]int{
@@ -63,13 +67,22 @@ func (f *searchTestSuite) TestBuildSearchIndex2() {
 	require.Equal(f.T(), f.secondExpectedCase, actual)
 }
 
+// postings returns the postings of a term which occurs once at the start of every document
+func postings(ids ...int) Postings {
+	p := make(Postings, 0, len(ids))
+	for _, id := range ids {
+		p = append(p, &Posting{DocID: id, Freq: 1, Positions: []int{0}})
+	}
+	return p
+}
+
 type indexTestSuite struct {
 	suite.Suite
 	wg          *sync.WaitGroup
 	index       Index
 	searchQuery []string
-	expected    map[string]string
-	dataChan    chan map[string]string
+	expected    *FileMap
+	dataChan    chan *FileMap
 	content     string
 	file        *os.File
 }
@@ -94,14 +107,35 @@ func (f *indexTestSuite) SetupSuite() {
 func (f *indexTestSuite) SetupTest() {
 	f.wg = &sync.WaitGroup{}
 	f.wg.Add(1)
-	f.dataChan = make(chan map[string]string, 10)
+	f.dataChan = make(chan *FileMap, 10)
+
+	hello := &Posting{DocID: 0, Freq: 10000}
+	world := &Posting{DocID: 0, Freq: 10000}
+	for i := 0; i < 10000; i++ {
+		hello.Positions = append(hello.Positions, 2*i)
+		world.Positions = append(world.Positions, 2*i+1)
+	}
 
-	f.index = make(Index)
-	f.index["hello"] = []string{f.file.Name()}
-	f.index["world"] = []string{f.file.Name()}
-	f.expected = make(map[string]string)
-	f.expected["hello"] = f.file.Name()
-	f.expected["world"] = f.file.Name()
+	doc, err := NewDocument(f.file.Name())
+	require.NoError(f.T(), err)
+	doc.Length = 20000
+
+	f.index = *NewIndex()
+	f.index.Terms["hello"] = Postings{hello}
+	f.index.Terms["world"] = Postings{world}
+	f.index.Docs[0] = doc
+	f.index.NextID = 1
+	f.expected = &FileMap{
+		Document: doc,
+		Postings: map[string]*Posting{
+			"hello": hello,
+			"world": world,
+		},
+		Surfaces: map[string]map[string]int{
+			"hello": {"hello": 10000},
+			"world": {"world": 10000},
+		},
+	}
 }
 
 func (f *indexTestSuite) TearDownTest() {
@@ -125,7 +159,7 @@ func (f *indexTestSuite) TestConcurrentBuildFileMap() {
 			require.Equal(f.T(), f.expected, data)
 		}
 	}()
-	ConcurrentBuildFileMap(f.wg, f.file.Name(), f.dataChan)
+	ConcurrentBuildFileMap(f.wg, 0, f.file.Name(), analysis.DefaultSettings(), f.dataChan)
 }
 
 func (f *indexTestSuite) TestAsyncConcurrentBuildFileMap() {
@@ -134,14 +168,39 @@ func (f *indexTestSuite) TestAsyncConcurrentBuildFileMap() {
 			require.Equal(f.T(), f.expected, data)
 		}
 	}()
-	go ConcurrentBuildFileMap(f.wg, f.file.Name(), f.dataChan)
+	go ConcurrentBuildFileMap(f.wg, 0, f.file.Name(), analysis.DefaultSettings(), f.dataChan)
 	f.wg.Add(1)
-	go ConcurrentBuildFileMap(f.wg, f.file.Name(), f.dataChan)
+	go ConcurrentBuildFileMap(f.wg, 0, f.file.Name(), analysis.DefaultSettings(), f.dataChan)
 	f.wg.Wait()
 }
 
 func (f *indexTestSuite) TestCreateInvertedIndex() {
-	m, err := CreateInvertedIndex([]string{f.file.Name()})
+	m, err := CreateInvertedIndex([]string{f.file.Name()}, analysis.DefaultSettings())
 	require.NoError(f.T(), err)
 	require.Equal(f.T(), f.index, *m)
 }
+
+func TestDocumentSetRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		root    string
+		relPath string
+		ext     string
+	}{
+		{name: "Nested file", path: "data/docs/Guide.MD", root: "data", relPath: "docs/Guide.MD", ext: "md"},
+		{name: "Root with slash", path: "/srv/data/a.txt", root: "/srv/data/", relPath: "a.txt", ext: "txt"},
+		{name: "File outside root", path: "other/a.txt", root: "data", relPath: "other/a.txt", ext: "txt"},
+		{name: "Root is the file", path: "data/a", root: "data/a", relPath: "a", ext: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Document{Path: tt.path}
+			doc.FillMetadata()
+			doc.SetRoot(tt.root)
+			if doc.RelPath != tt.relPath || doc.Ext != tt.ext {
+				t.Errorf("SetRoot() got = %q, %q, want %q, %q", doc.RelPath, doc.Ext, tt.relPath, tt.ext)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package index
+
+import (
+	"sort"
+)
+
+// MatchPhrase returns the structure where the key is the document ID, and the value is the number of times the cleaned
+// terms occur in this document in the same order at the same distance as their offsets. The offsets are the positions
+// of the terms in the phrase, the terms are consecutive if they are nil. Documents without the phrase are not returned
+func (m *Index) MatchPhrase(terms []string, offsets []int) map[int]int {
+
+	ans := make(map[int]int)
+	if len(terms) == 0 {
+		return ans
+	}
+
+	// positions of every phrase term in every document containing it
+	positions := make([]map[int][]int, len(terms))
+	for i, t := range terms {
+		postings, ok := m.Terms[t]
+		if !ok {
+			return ans
+		}
+		positions[i] = make(map[int][]int, len(postings))
+		for _, p := range postings {
+			positions[i][p.DocID] = p.Positions
+		}
+	}
+
+	for id, firstPositions := range positions[0] {
+		var count int
+	PositionLoop:
+		for _, start := range firstPositions {
+			for i := 1; i < len(terms); i++ {
+				offset := i
+				if offsets != nil {
+					offset = offsets[i] - offsets[0]
+				}
+				if !containsPosition(positions[i][id], start+offset) {
+					continue PositionLoop
+				}
+			}
+			count++
+		}
+		if count > 0 {
+			ans[id] = count
+		}
+	}
+
+	return ans
+}
+
+// containsPosition reports whether the sorted positions contain the position
+func containsPosition(positions []int, position int) bool {
+	i := sort.SearchInts(positions, position)
+	return i < len(positions) && positions[i] == position
+}
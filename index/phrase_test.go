@@ -0,0 +1,61 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type phraseTestSuite struct {
+	suite.Suite
+	index *Index
+}
+
+func TestPhraseTestSuite(t *testing.T) {
+	suite.Run(t, new(phraseTestSuite))
+}
+
+func (f *phraseTestSuite) SetupTest() {
+	f.index = NewIndex()
+	// file1: "invert index invert index", file2: "index invert", file3: "invert search index"
+	f.index.Terms["invert"] = Postings{
+		{DocID: 1, Freq: 2, Positions: []int{0, 2}},
+		{DocID: 2, Freq: 1, Positions: []int{1}},
+		{DocID: 3, Freq: 1, Positions: []int{0}},
+	}
+	f.index.Terms["index"] = Postings{
+		{DocID: 1, Freq: 2, Positions: []int{1, 3}},
+		{DocID: 2, Freq: 1, Positions: []int{0}},
+		{DocID: 3, Freq: 1, Positions: []int{2}},
+	}
+	f.index.Terms["search"] = Postings{
+		{DocID: 3, Freq: 1, Positions: []int{1}},
+	}
+}
+
+func (f *phraseTestSuite) TestMatchPhrase() {
+	actual := f.index.MatchPhrase([]string{"invert", "index"}, nil)
+	require.Equal(f.T(), map[int]int{1: 2}, actual)
+}
+
+func (f *phraseTestSuite) TestMatchPhraseOrder() {
+	actual := f.index.MatchPhrase([]string{"index", "invert"}, nil)
+	require.Equal(f.T(), map[int]int{1: 1, 2: 1}, actual)
+}
+
+func (f *phraseTestSuite) TestMatchLongPhrase() {
+	actual := f.index.MatchPhrase([]string{"invert", "search", "index"}, nil)
+	require.Equal(f.T(), map[int]int{3: 1}, actual)
+}
+
+func (f *phraseTestSuite) TestMatchPhraseUnknownTerm() {
+	actual := f.index.MatchPhrase([]string{"invert", "golang"}, nil)
+	require.Empty(f.T(), actual)
+}
+
+func (f *phraseTestSuite) TestMatchPhraseWithGap() {
+	// the word between the terms is dropped from the phrase, like the stop word
+	actual := f.index.MatchPhrase([]string{"invert", "index"}, []int{0, 2})
+	require.Equal(f.T(), map[int]int{3: 1}, actual)
+}
@@ -0,0 +1,213 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotFound is returned when the document isn't indexed
+var ErrNotFound = errors.New("document not found")
+
+// Changes is the difference between the indexed documents and the files on disk
+type Changes struct {
+	// Added are the files which aren't indexed yet
+	Added []string
+	// Changed are the indexed files whose content changed
+	Changed []string
+	// Removed are the indexed files which don't exist anymore
+	Removed []string
+	// Touched are the indexed files whose size or modification time changed but the content didn't, value is the
+	// new description of the file with the same document ID
+	Touched map[string]*Document
+}
+
+// Diff compares the indexed documents with the files and returns which of them must be reindexed or removed. The
+// content hash is computed only for the files whose size or modification time changed
+func Diff(docs map[int]*Document, files []string) (*Changes, error) {
+
+	c := &Changes{
+		Touched: make(map[string]*Document),
+	}
+
+	paths := make(map[string]*Document, len(docs))
+	for _, doc := range docs {
+		paths[doc.Path] = doc
+	}
+
+	exist := make(map[string]struct{}, len(files))
+	for _, filename := range files {
+		exist[filename] = struct{}{}
+
+		indexed, ok := paths[filename]
+		if !ok {
+			c.Added = append(c.Added, filename)
+			continue
+		}
+
+		doc, err := NewDocument(filename)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case doc.Size == indexed.Size && doc.ModTime.Equal(indexed.ModTime):
+		case doc.Hash == indexed.Hash:
+			doc.ID = indexed.ID
+			doc.Length = indexed.Length
+			doc.RelPath = indexed.RelPath
+			c.Touched[filename] = doc
+		default:
+			c.Changed = append(c.Changed, filename)
+		}
+	}
+
+	for filename := range paths {
+		if _, ok := exist[filename]; !ok {
+			c.Removed = append(c.Removed, filename)
+		}
+	}
+	sort.Strings(c.Removed)
+
+	log.Debug().
+		Strs("added", c.Added).
+		Strs("changed", c.Changed).
+		Strs("removed", c.Removed).
+		Int("touched", len(c.Touched)).
+		Msg("index changes found")
+
+	return c, nil
+}
+
+// Reindexed returns the files which must be read to bring the index up to date
+func (c *Changes) Reindexed() []string {
+	return append(append([]string{}, c.Added...), c.Changed...)
+}
+
+// Outdated returns the indexed files whose postings must be removed
+func (c *Changes) Outdated() []string {
+	return append(append([]string{}, c.Changed...), c.Removed...)
+}
+
+// Empty reports whether the index is up to date
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0 && len(c.Touched) == 0
+}
+
+// Update brings the index in line with the files: indexes the added and changed files and removes the deleted ones.
+// Changed files keep their document IDs
+func (m *Index) Update(files []string) (*Changes, error) {
+
+	c, err := Diff(m.Docs, files)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := m.Paths()
+	for _, filename := range c.Outdated() {
+		m.RemoveDocument(paths[filename].ID)
+	}
+	for _, doc := range c.Touched {
+		m.Docs[doc.ID] = doc
+	}
+
+	if reindexed := c.Reindexed(); len(reindexed) > 0 {
+		other, err := CreateInvertedIndex(reindexed, m.Settings)
+		if err != nil {
+			return nil, err
+		}
+		m.Merge(other, paths)
+	}
+
+	return c, nil
+}
+
+// UpdateDocument reindexes the document keeping its ID
+func (m *Index) UpdateDocument(id int) error {
+
+	doc, ok := m.Docs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if _, err := os.Stat(doc.Path); err != nil {
+		return err
+	}
+
+	other, err := CreateInvertedIndex([]string{doc.Path}, m.Settings)
+	if err != nil {
+		return err
+	}
+	if len(other.Docs) == 0 {
+		return fmt.Errorf("can't index file %s", doc.Path)
+	}
+
+	m.RemoveDocument(id)
+	m.Merge(other, map[string]*Document{doc.Path: doc})
+	return nil
+}
+
+// Merge adds the documents and postings of the other index. The documents whose paths are in previous get the IDs
+// and the relative paths of the previous documents, the rest of them get new IDs. The files of the other index must
+// not be in the index. The postings of the index are never changed in place. The new terms get the surface forms of
+// the other index, the terms of the index keep theirs
+func (m *Index) Merge(other *Index, previous map[string]*Document) {
+
+	other.Renumber(func(doc *Document) int {
+		if prev, ok := previous[doc.Path]; ok {
+			return prev.ID
+		}
+		id := m.NextID
+		m.NextID++
+		return id
+	})
+
+	for id, doc := range other.Docs {
+		// the file keeps the path relative to the directory it was indexed in
+		if prev, ok := previous[doc.Path]; ok && prev.RelPath != "" {
+			doc.RelPath = prev.RelPath
+		}
+		m.Docs[id] = doc
+		if id >= m.NextID {
+			m.NextID = id + 1
+		}
+	}
+	// the postings are merged into the new slices, because the stored ones may be read by the searches
+	for term, postings := range other.Terms {
+		if _, ok := m.Terms[term]; !ok {
+			m.setSurface(term, other.Surface(term))
+		}
+		merged := make(Postings, 0, len(m.Terms[term])+len(postings))
+		merged = append(append(merged, m.Terms[term]...), postings...)
+		merged.sort()
+		m.Terms[term] = merged
+	}
+}
+
+// RemoveDocument removes the document and its postings from the index and returns the terms which lost their postings
+func (m *Index) RemoveDocument(id int) []string {
+
+	delete(m.Docs, id)
+
+	var affected []string
+	for term, postings := range m.Terms {
+		i := sort.Search(len(postings), func(i int) bool {
+			return postings[i].DocID >= id
+		})
+		if i == len(postings) || postings[i].DocID != id {
+			continue
+		}
+
+		affected = append(affected, term)
+		if len(postings) == 1 {
+			delete(m.Terms, term)
+			delete(m.Surfaces, term)
+			continue
+		}
+		m.Terms[term] = append(postings[:i:i], postings[i+1:]...)
+	}
+
+	sort.Strings(affected)
+	return affected
+}
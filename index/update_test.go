@@ -0,0 +1,146 @@
+package index
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type updateTestSuite struct {
+	suite.Suite
+	dir   string
+	files []string
+	index *Index
+}
+
+func TestUpdateTestSuite(t *testing.T) {
+	suite.Run(t, new(updateTestSuite))
+}
+
+func (f *updateTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(f.T(), err)
+	f.dir = dir
+
+	f.files = []string{f.write("file1", "hello world"), f.write("file2", "golang world"), f.write("file3", "java")}
+	f.index, err = CreateInvertedIndex(f.files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+}
+
+func (f *updateTestSuite) TearDownTest() {
+	require.NoError(f.T(), os.RemoveAll(f.dir))
+}
+
+func (f *updateTestSuite) write(name, content string) string {
+	filename := filepath.Join(f.dir, name)
+	require.NoError(f.T(), ioutil.WriteFile(filename, []byte(content), 0644))
+	return filename
+}
+
+func (f *updateTestSuite) TestUpToDate() {
+	changes, err := f.index.Update(f.files)
+	require.NoError(f.T(), err)
+	require.True(f.T(), changes.Empty())
+}
+
+func (f *updateTestSuite) TestUpdate() {
+	changed := f.write("file1", "hello golang architecture")
+	require.NoError(f.T(), os.Chtimes(changed, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(f.T(), os.Remove(f.files[1]))
+	added := f.write("file4", "world")
+	touched := f.files[2]
+	require.NoError(f.T(), os.Chtimes(touched, time.Now(), time.Now().Add(time.Minute)))
+
+	files := []string{changed, touched, added}
+	changes, err := f.index.Update(files)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{added}, changes.Added)
+	require.Equal(f.T(), []string{changed}, changes.Changed)
+	require.Equal(f.T(), []string{f.files[1]}, changes.Removed)
+	require.Contains(f.T(), changes.Touched, touched)
+
+	// changed and touched files keep their IDs, added files get new ones
+	paths := f.index.Paths()
+	require.Equal(f.T(), 0, paths[changed].ID)
+	require.Equal(f.T(), 2, paths[touched].ID)
+	require.Equal(f.T(), 3, paths[added].ID)
+	require.Equal(f.T(), 4, f.index.NextID)
+
+	expected, err := CreateInvertedIndex(files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), byPath(expected), byPath(f.index))
+}
+
+func (f *updateTestSuite) TestRemoveDocument() {
+	affected := f.index.RemoveDocument(0)
+	require.Equal(f.T(), []string{"hello", "world"}, affected)
+	require.NotContains(f.T(), f.index.Terms, "hello")
+	require.Equal(f.T(), Postings{{DocID: 1, Freq: 1, Positions: []int{1}}}, f.index.Terms["world"])
+	require.NotContains(f.T(), f.index.Docs, 0)
+}
+
+func (f *updateTestSuite) TestUpdateDocument() {
+	f.write("file3", "java golang")
+	require.NoError(f.T(), f.index.UpdateDocument(2))
+
+	expected, err := CreateInvertedIndex(f.files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), expected, f.index)
+}
+
+func (f *updateTestSuite) TestUpdateMissingDocument() {
+	require.Equal(f.T(), ErrNotFound, f.index.UpdateDocument(5))
+
+	require.NoError(f.T(), os.Remove(f.files[2]))
+	require.True(f.T(), os.IsNotExist(f.index.UpdateDocument(2)))
+}
+
+func (f *updateTestSuite) TestSurfaces() {
+	files := []string{f.write("file1", "Compared comparing compare"), f.write("file2", "compare hello")}
+	idx, err := CreateInvertedIndex(files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), map[string]string{"compar": "compare"}, idx.Surfaces)
+	require.Equal(f.T(), "compare", idx.Dictionary().Surface("compar"))
+	require.Equal(f.T(), "hello", idx.Dictionary().Surface("hello"))
+
+	// the stored term keeps its surface form, the new one gets the form of the reindexed file
+	f.write("file2", "comparing worlds")
+	require.NoError(f.T(), idx.UpdateDocument(1))
+	require.Equal(f.T(), map[string]string{"compar": "compare", "world": "worlds"}, idx.Surfaces)
+
+	idx.RemoveDocument(1)
+	require.Equal(f.T(), map[string]string{"compar": "compare"}, idx.Surfaces)
+}
+
+func (f *updateTestSuite) TestMergeKeepsStoredPostings() {
+	// the stored postings have room to grow, so appending to them would write into the slice read by the search
+	stored := make(Postings, 1, 4)
+	stored[0] = &Posting{DocID: 1, Freq: 1, Positions: []int{0}}
+	f.index.Terms["golang"] = stored
+	read := f.index.Terms["golang"]
+
+	f.write("file1", "golang")
+	require.NoError(f.T(), f.index.UpdateDocument(0))
+	require.Equal(f.T(), []int{0, 1}, []int{f.index.Terms["golang"][0].DocID, f.index.Terms["golang"][1].DocID})
+	require.Equal(f.T(), Postings{{DocID: 1, Freq: 1, Positions: []int{0}}}, read)
+}
+
+// byPath returns the index where documents are identified by their paths instead of IDs
+func byPath(idx *Index) map[string]map[string][]int {
+	ans := make(map[string]map[string][]int)
+	for _, doc := range idx.Docs {
+		ans[doc.Path] = map[string][]int{}
+	}
+	for term, postings := range idx.Terms {
+		for _, p := range postings {
+			ans[idx.Docs[p.DocID].Path][term] = p.Positions
+		}
+	}
+	return ans
+}
@@ -3,8 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/polisgo2020/search-Arkronzxc/db"
 
 	"github.com/polisgo2020/search-Arkronzxc/config"
@@ -13,6 +18,8 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/polisgo2020/search-Arkronzxc/query"
+	"github.com/polisgo2020/search-Arkronzxc/synonym"
 
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
@@ -38,6 +45,28 @@ func main() {
 		Required: true,
 	}
 
+	incrementalFlag := &cli.BoolFlag{
+		Aliases: []string{"i"},
+		Name:    "incremental",
+		Usage:   "Reindex only added and changed files and remove deleted ones",
+	}
+
+	indexFlag := &cli.StringFlag{
+		Name:  "index",
+		Usage: "Segment file with index, the store from the config is used if it's not set",
+	}
+
+	langFlag := &cli.StringFlag{
+		Name:  "lang",
+		Usage: "Language of the query, it's detected if not set",
+	}
+
+	queryFlag := &cli.StringFlag{
+		Aliases: []string{"q"},
+		Name:    "query",
+		Usage:   "Query to search in the index instead of starting the web server",
+	}
+
 	app.Commands = []*cli.Command{
 		{
 			Name:    "build",
@@ -45,6 +74,8 @@ func main() {
 			Usage:   "Build search index",
 			Flags: []cli.Flag{
 				sourcesFlag,
+				incrementalFlag,
+				indexFlag,
 			},
 			Action: build,
 		},
@@ -52,8 +83,12 @@ func main() {
 			Name:    "search",
 			Aliases: []string{"s"},
 			Usage:   "Search over the index",
-			Flags:   []cli.Flag{},
-			Action:  search,
+			Flags: []cli.Flag{
+				queryFlag,
+				langFlag,
+				indexFlag,
+			},
+			Action: search,
 		},
 	}
 
@@ -74,58 +109,264 @@ func initLogger(c *config.Config) error {
 }
 
 func build(ctx *cli.Context) error {
-	repo, err := db.NewIndexRepository(config.Load())
-	if err != nil {
-		return err
-	}
-
 	log.Info().Msg("build option chosen")
 
 	log.Debug().
-
 		Str("files to index in dir", ctx.String("sources")).
 		Str("output file with index", ctx.String("index")).
-
 		Msg("build option")
 
-	if nameSlice, err := readFileNames(ctx.String("sources")); err != nil {
+	c := config.Load()
+	settings, err := analysisSettings(c)
+	if err != nil {
+		return fmt.Errorf("error while loading analysis settings: %w", err)
+	}
+
+	repo, err := openStore(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	nameSlice, err := readFileNames(ctx.String("sources"))
+	if err != nil {
 		return fmt.Errorf("error while reading file names: %w", err)
-	} else {
-		invertedIndex, err := index.CreateInvertedIndex(nameSlice)
+	}
+
+	if ctx.Bool("incremental") {
+		stored, err := repo.Settings()
 		if err != nil {
-			return fmt.Errorf("error while creating inverted index: %w", err)
+			return fmt.Errorf("error while getting index settings: %w", err)
 		}
-		if err = repo.SaveIndex(*invertedIndex); err != nil {
-			return fmt.Errorf("error while creating output json: %w", err)
+		if stored.Equal(settings) {
+			if err = updateIndex(repo, ctx.String("sources"), nameSlice, settings); err != nil {
+				return fmt.Errorf("error while updating index: %w", err)
+			}
+			log.Debug().Msg("build successfully completed")
+			return nil
 		}
+		log.Warn().
+			Interface("stored", stored).
+			Interface("configured", settings).
+			Msg("analysis settings are changed, the whole index is rebuilt")
+	}
+
+	invertedIndex, err := index.CreateInvertedIndex(nameSlice, settings)
+	if err != nil {
+		return fmt.Errorf("error while creating inverted index: %w", err)
+	}
+	invertedIndex.SetRoot(ctx.String("sources"))
+	if err = repo.SaveIndex(*invertedIndex); err != nil {
+		return fmt.Errorf("error while creating output json: %w", err)
 	}
 
 	log.Debug().Msg("build successfully completed")
 	return nil
 }
 
+// analysisSettings returns the analysis settings of the built index from the config. Stop words from the files and
+// from the config are merged and sorted, so the same config always gives the equal settings
+func analysisSettings(c *config.Config) (analysis.Settings, error) {
+	settings := analysis.Settings{
+		Analyzer:    c.Analyzer,
+		Language:    analysis.Language(c.Language),
+		NoStopWords: c.StopWordsDisabled,
+	}
+
+	if !c.StopWordsDisabled && (len(c.StopWordFiles) > 0 || len(c.StopWords) > 0) {
+		settings.StopWords = make(map[analysis.Language][]string)
+		for lang, filename := range c.StopWordFiles {
+			l, err := analysis.ParseLanguage(lang)
+			if err != nil {
+				return analysis.Settings{}, err
+			}
+			words, err := analysis.LoadStopWords(filename)
+			if err != nil {
+				return analysis.Settings{}, err
+			}
+			settings.StopWords[l] = append(settings.StopWords[l], words...)
+		}
+		for lang, words := range c.StopWords {
+			l, err := analysis.ParseLanguage(lang)
+			if err != nil {
+				return analysis.Settings{}, err
+			}
+			settings.StopWords[l] = append(settings.StopWords[l], words...)
+		}
+		for l, words := range settings.StopWords {
+			settings.StopWords[l] = uniqueSorted(words)
+		}
+	}
+
+	return settings, settings.Validate()
+}
+
+// uniqueSorted returns the sorted lowercase words without duplicates
+func uniqueSorted(words []string) []string {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[strings.ToLower(w)] = struct{}{}
+	}
+	ans := make([]string, 0, len(set))
+	for w := range set {
+		ans = append(ans, w)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+// openStore returns the store from the config, the segment file from the index flag overrides it
+func openStore(ctx *cli.Context, c *config.Config) (db.Store, error) {
+	if input := ctx.String("index"); input != "" {
+		c.Store = config.StoreFile
+		c.IndexFile = input
+	}
+
+	log.Debug().Str("store", c.Store).Msg("opening store")
+	repo, err := db.NewStore(c)
+	if err != nil {
+		log.Err(err).Str("store", c.Store).Msg("error while opening store")
+		return nil, err
+	}
+	return repo, nil
+}
+
+// updateIndex reindexes only the added and changed files and removes the deleted ones from the stored index. Paths of
+// the documents are made relative to the root
+func updateIndex(repo db.Store, root string, nameSlice []string, settings analysis.Settings) error {
+	docs, err := repo.GetDocuments()
+	if err != nil {
+		return err
+	}
+
+	changes, err := index.Diff(docs, nameSlice)
+	if err != nil {
+		return err
+	}
+	if changes.Empty() {
+		log.Info().Msg("index is up to date")
+		return nil
+	}
+
+	invertedIndex, err := index.CreateInvertedIndex(changes.Reindexed(), settings)
+	if err != nil {
+		return err
+	}
+	invertedIndex.SetRoot(root)
+
+	log.Info().
+		Int("added", len(changes.Added)).
+		Int("changed", len(changes.Changed)).
+		Int("removed", len(changes.Removed)).
+		Msg("applying index changes")
+
+	return repo.UpdateIndex(*invertedIndex, changes)
+}
+
 func search(ctx *cli.Context) error {
 
 	c := config.Load()
 
-	input := ctx.String("index")
 	log.Info().Msg("starting searching")
 
-	log.Debug().Str("input", input)
+	repo, err := openStore(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	synonyms, err := loadSynonyms(c)
+	if err != nil {
+		return fmt.Errorf("error while loading synonyms: %w", err)
+	}
+
+	if ctx.IsSet("query") {
+		return searchQuery(repo, synonyms.Dictionary(), c, ctx.String("query"), ctx.String("lang"))
+	}
+
+	if synonyms != nil {
+		go reloadOnHangup(synonyms)
+	}
+
+	log.Info().Msg("handler is complete")
+
+	return web.StartingWeb(repo, synonyms, c)
+}
+
+// loadSynonyms returns the synonym dictionary from the config or nil if it isn't configured
+func loadSynonyms(c *config.Config) (*synonym.File, error) {
+	if c.SynonymsFile == "" {
+		return nil, nil
+	}
+	return synonym.NewFile(c.SynonymsFile)
+}
 
-	searchIndex, err := index.UnmarshalFile(input)
+// reloadOnHangup reloads the synonym dictionary every time the process gets SIGHUP
+func reloadOnHangup(synonyms *synonym.File) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		log.Info().Msg("reloading synonyms")
+		// the previous dictionary is kept on error, which is already logged
+		_ = synonyms.Reload()
+	}
+}
 
+// searchQuery evaluates the query against the index and prints the matching files
+func searchQuery(repo db.Store, synonyms *synonym.Dictionary, c *config.Config, rawQuery string, langName string) error {
+
+	settings, err := repo.Settings()
+	if err != nil {
+		return fmt.Errorf("error while getting index settings: %w", err)
+	}
+	lang, err := settings.LanguageOf(langName, rawQuery)
+	if err != nil {
+		return err
+	}
+	analyzer, err := settings.New(lang)
 	if err != nil {
-		log.Err(err)
 		return err
 	}
 
-	log.Debug().Interface("index", searchIndex)
+	parser := &query.Parser{
+		Analyzer:      analyzer,
+		Settings:      settings,
+		Synonyms:      synonyms,
+		SynonymWeight: c.SynonymWeight,
+		TypoTolerant:  c.TypoTolerant,
+	}
+	parsedQuery, err := parser.Parse(rawQuery)
+	if err != nil {
+		return fmt.Errorf("error while parsing query: %w", err)
+	}
 
-	log.Info().Msg("handler is complete")
+	dict, err := repo.Dictionary()
+	if err != nil {
+		return fmt.Errorf("error while getting term dictionary: %w", err)
+	}
+	query.Expand(parsedQuery, dict, c.MaxExpansions)
 
-	return web.StartingWeb(searchIndex, c)
+	searchIndex, missing, err := repo.GetIndex(query.Terms(parsedQuery), query.NeedsAllDocuments(parsedQuery))
+	if err != nil {
+		return fmt.Errorf("error while getting index: %w", err)
+	}
+	if len(missing) > 0 {
+		log.Info().Strs("terms", missing).Msg("no results for terms")
+	}
 
+	hits := query.Search(searchIndex, parsedQuery, index.BM25{
+		K1: c.BM25K1,
+		B:  c.BM25B,
+	})
+	for _, h := range hits {
+		fmt.Printf("%.4f\t%d\t%s\n", h.Score, h.WordsEncountered, h.Document.Path)
+	}
+	if len(hits) == 0 {
+		if corrected, ok := query.Correct(rawQuery, analyzer, dict); ok {
+			fmt.Printf("did you mean: %s\n", corrected)
+		}
+	}
+	return nil
 }
 
 // Returns slice of file names from dir
@@ -142,5 +383,3 @@ func readFileNames(root string) ([]string, error) {
 	log.Debug().Strs("files", files)
 	return files, err
 }
-
-
@@ -0,0 +1,188 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// Clause is the node of the structured query written as JSON, exactly one of its fields is set:
+//
+//	{"query": "golang -java"}                     - query in the query syntax
+//	{"term": "golang"}                            - word expanded with the synonyms and typos like in the query
+//	{"phrase": "hello world"}                     - phrase
+//	{"fuzzy": {"word": "golnag", "distance": 1}}  - fuzzy term, the omitted distance is chosen by the word length
+//	{"wildcard": "go*"}                           - wildcard term
+//	{"range": {"gte": "a", "lt": "m"}}            - range of terms, the omitted bounds are open
+//	{"and": [...]}, {"or": [...]}, {"not": {...}} - boolean combinations of the clauses
+type Clause struct {
+	Query    string       `json:"query,omitempty"`
+	Term     string       `json:"term,omitempty"`
+	Phrase   string       `json:"phrase,omitempty"`
+	Fuzzy    *FuzzyClause `json:"fuzzy,omitempty"`
+	Wildcard string       `json:"wildcard,omitempty"`
+	Range    *RangeClause `json:"range,omitempty"`
+	And      []*Clause    `json:"and,omitempty"`
+	Or       []*Clause    `json:"or,omitempty"`
+	Not      *Clause      `json:"not,omitempty"`
+}
+
+// FuzzyClause is the word matching the terms within the edit distance
+type FuzzyClause struct {
+	Word     string `json:"word"`
+	Distance *int   `json:"distance,omitempty"`
+}
+
+// RangeClause is the range of terms, Gt and Gte are the lower bound excluded and included, Lt and Lte are the upper
+// ones
+type RangeClause struct {
+	Gt  string `json:"gt,omitempty"`
+	Gte string `json:"gte,omitempty"`
+	Lt  string `json:"lt,omitempty"`
+	Lte string `json:"lte,omitempty"`
+}
+
+// Text returns the words of the clause separated by spaces, it's used to detect the language of the query
+func (c *Clause) Text() string {
+	var words []string
+	c.visit(func(text string, _ bool) {
+		words = append(words, text)
+	})
+	return strings.Join(words, " ")
+}
+
+// visit calls f for the text of every query, term and phrase of the clause, query reports whether the text is in the
+// query syntax
+func (c *Clause) visit(f func(text string, query bool)) {
+	if c == nil {
+		return
+	}
+	switch {
+	case c.Query != "":
+		f(c.Query, true)
+	case c.Term != "":
+		f(c.Term, false)
+	case c.Phrase != "":
+		f(c.Phrase, false)
+	}
+	for _, sub := range c.And {
+		sub.visit(f)
+	}
+	for _, sub := range c.Or {
+		sub.visit(f)
+	}
+	c.Not.visit(f)
+}
+
+// ParseClause returns the query tree of the structured query. The words are analyzed and expanded the same way as the
+// words of the parsed query. The error tells the path of the invalid clause. ParseClause returns nil node if the
+// clause has no words to search
+func (pp *Parser) ParseClause(c *Clause) (Node, error) {
+	return (&parser{Parser: pp}).clause(c, "query")
+}
+
+func (p *parser) clause(c *Clause, path string) (Node, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s: clause is empty", ErrSyntax, path)
+	}
+	if n := c.fieldCount(); n != 1 {
+		return nil, fmt.Errorf("%w: %s: clause must have exactly one of query, term, phrase, fuzzy, wildcard, range, "+
+			"and, or, not, got %d", ErrSyntax, path, n)
+	}
+
+	switch {
+	case c.Query != "":
+		n, err := p.Parser.Parse(c.Query)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return n, nil
+
+	case c.Term != "":
+		return p.expand(c.Term, true)
+
+	case c.Phrase != "":
+		return p.expand(c.Phrase, false)
+
+	case c.Fuzzy != nil:
+		distance := AutoDistance
+		if c.Fuzzy.Distance != nil {
+			distance = *c.Fuzzy.Distance
+			if distance < 0 || distance > index.MaxDistance {
+				return nil, fmt.Errorf("%w: %s.fuzzy: distance must be from 0 to %d", ErrSyntax, path,
+					index.MaxDistance)
+			}
+		}
+		if c.Fuzzy.Word == "" {
+			return nil, fmt.Errorf("%w: %s.fuzzy: word is empty", ErrSyntax, path)
+		}
+		return p.fuzzy(c.Fuzzy.Word, distance)
+
+	case c.Wildcard != "":
+		return p.wildcard(c.Wildcard), nil
+
+	case c.Range != nil:
+		return c.Range.node(path+".range", p.Settings.NormalizePattern)
+
+	case c.Not != nil:
+		n, err := p.clause(c.Not, path+".not")
+		if err != nil || n == nil {
+			return nil, err
+		}
+		return &Not{Node: n}, nil
+
+	default:
+		name, subs := "and", c.And
+		if len(c.Or) > 0 {
+			name, subs = "or", c.Or
+		}
+		var nodes []Node
+		for i, sub := range subs {
+			n, err := p.clause(sub, fmt.Sprintf("%s.%s[%d]", path, name, i))
+			if err != nil {
+				return nil, err
+			}
+			if n != nil {
+				nodes = append(nodes, n)
+			}
+		}
+		switch {
+		case len(nodes) == 0:
+			return nil, nil
+		case len(nodes) == 1:
+			return nodes[0], nil
+		case name == "or":
+			return &Or{Nodes: nodes}, nil
+		default:
+			return &And{Nodes: nodes}, nil
+		}
+	}
+}
+
+// fieldCount returns the number of the fields set in the clause
+func (c *Clause) fieldCount() int {
+	var ans int
+	for _, set := range []bool{
+		c.Query != "", c.Term != "", c.Phrase != "", c.Fuzzy != nil, c.Wildcard != "", c.Range != nil,
+		len(c.And) > 0, len(c.Or) > 0, c.Not != nil,
+	} {
+		if set {
+			ans++
+		}
+	}
+	return ans
+}
+
+// node returns the range node of the bounds normalized by normalize
+func (r *RangeClause) node(path string, normalize func(string) string) (Node, error) {
+	if r.Gt != "" && r.Gte != "" || r.Lt != "" && r.Lte != "" {
+		return nil, fmt.Errorf("%w: %s: bound must be set by only one of gt and gte, lt and lte", ErrSyntax, path)
+	}
+	return &Range{
+		From:        normalize(r.Gt + r.Gte),
+		To:          normalize(r.Lt + r.Lte),
+		IncludeFrom: r.Gt == "",
+		IncludeTo:   r.Lt == "",
+	}, nil
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+func TestParseClause(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "Term", input: `{"term": "Freezing"}`, want: "freez"},
+		{
+			name:  "Boolean tree",
+			input: `{"and": [{"phrase": "hello world"}, {"or": [{"term": "golang"}, {"wildcard": "Ja*"}]}]}`,
+			want:  `("hello world" AND (golang OR ja*))`,
+		},
+		{name: "Not", input: `{"not": {"query": "java -rust"}}`, want: "NOT (java -rust)"},
+		{name: "Fuzzy", input: `{"fuzzy": {"word": "golnag", "distance": 1}}`, want: "golnag~1"},
+		{name: "Auto distance", input: `{"fuzzy": {"word": "golnag"}}`, want: "golnag~"},
+		{name: "Range", input: `{"range": {"gt": "a", "lte": "m"}}`, want: "{a TO m]"},
+		{name: "Stop words", input: `{"or": [{"term": "the"}, {"term": "world"}]}`, want: "world"},
+		{name: "Several fields", input: `{"term": "a", "phrase": "b c"}`, wantErr: "query: clause must have"},
+		{name: "No fields", input: `{"and": [{"term": "a"}, {}]}`, wantErr: "query.and[1]: clause must have"},
+		{name: "Null clause", input: `{"or": [null]}`, wantErr: "query.or[0]: clause is empty"},
+		{name: "Invalid query", input: `{"not": {"query": "(java"}}`, wantErr: "query.not: query syntax error"},
+		{name: "Too large distance", input: `{"fuzzy": {"word": "golang", "distance": 3}}`, wantErr: "query.fuzzy"},
+		{name: "Both bounds", input: `{"range": {"gt": "a", "gte": "b"}}`, wantErr: "query.range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Clause
+			if err := json.Unmarshal([]byte(tt.input), &c); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got, err := (&Parser{Analyzer: analysis.ForLanguage(analysis.English)}).ParseClause(&c)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrSyntax) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseClause() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseClause() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseClause() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestClauseText(t *testing.T) {
+	c := &Clause{Or: []*Clause{
+		{Term: "the"},
+		{Not: &Clause{Phrase: "world of golang"}},
+		{Query: `"is it" AND java`},
+		{Wildcard: "th*"},
+	}}
+	if want := "the world of golang \"is it\" AND java"; c.Text() != want {
+		t.Errorf("Text() = %q, want %q", c.Text(), want)
+	}
+}
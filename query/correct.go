@@ -0,0 +1,62 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// Correct returns the query with the misspelled words replaced by the surface forms of the closest terms of the
+// dictionary, ok reports whether any word is replaced. Only the plain words are corrected, operators, phrases, fuzzy,
+// wildcard and range terms and filters are kept as they are
+func Correct(input string, analyzer analysis.Analyzer, dict *index.Dictionary) (corrected string, ok bool) {
+	runes := []rune(input)
+
+	var b strings.Builder
+	var last int
+	for _, t := range lex(input) {
+		if t.kind != tokenWord || strings.ContainsAny(t.value, "*?~") || isFilter(t.value) {
+			continue
+		}
+		words, err := analyzer.Analyze(t.value)
+		if err != nil || len(words) != 1 || dict.Contains(words[0]) {
+			continue
+		}
+		term, found := closest(dict, words[0])
+		if !found {
+			continue
+		}
+
+		b.WriteString(string(runes[last:t.start]))
+		b.WriteString(dict.Surface(term))
+		last = t.end
+		ok = true
+	}
+	if !ok {
+		return input, false
+	}
+
+	b.WriteString(string(runes[last:]))
+	return b.String(), true
+}
+
+// closest returns the term of the dictionary within the edit distance tolerated in the word. The closest terms win,
+// the terms at the same distance are ordered by the document frequency
+func closest(dict *index.Dictionary, word string) (string, bool) {
+	matches := dict.Fuzzy(word, autoDistance(word))
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.Distance > best.Distance {
+			break
+		}
+		if dict.DocFreq(m.Term) > dict.DocFreq(best.Term) {
+			best = m
+		}
+	}
+	return best.Term, true
+}
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+func TestCorrect(t *testing.T) {
+	dict := index.NewFreqDictionary(map[string]int{
+		"golang": 3, "world": 2, "word": 5, "java": 1, "hello": 1,
+	})
+
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOk bool
+	}{
+		{name: "Misspelled word", input: "golnag world", want: "golang world", wantOk: true},
+		{name: "Operators are kept", input: "+helo AND (jawa OR rust)", want: "+hello AND (java OR rust)", wantOk: true},
+		{name: "More frequent term wins", input: "wold", want: "word", wantOk: true},
+		{name: "Short words aren't corrected", input: "wo", want: "wo"},
+		{name: "Known words", input: "hello world", want: "hello world"},
+		{
+			name:  "Special terms are kept",
+			input: `"helo world" golan~1 jav* [a TO b]`,
+			want:  `"helo world" golan~1 jav* [a TO b]`,
+		},
+		{name: "Stop words are kept", input: "the golang", want: "the golang"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Correct(tt.input, analysis.ForLanguage(analysis.English), dict)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Correct() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCorrectSurfaces(t *testing.T) {
+	dict := index.NewFreqDictionary(map[string]int{"index": 2, "invert": 3})
+	dict.SetSurfaces(map[string]string{"invert": "inverted"})
+
+	got, ok := Correct("indx invertd", analysis.ForLanguage(analysis.English), dict)
+	if want := "index inverted"; got != want || !ok {
+		t.Errorf("Correct() = %q, %v, want %q, true", got, ok, want)
+	}
+}
@@ -0,0 +1,159 @@
+package query
+
+import "github.com/polisgo2020/search-Arkronzxc/index"
+
+// DefaultMaxExpansions is the default maximum number of the index terms one query term is expanded to
+const DefaultMaxExpansions = 50
+
+// Wildcard matches the documents containing the index terms matching the pattern, '*' matches any number of letters
+// and '?' matches exactly one letter. The terms are found by Expand
+type Wildcard struct {
+	Pattern string
+	Terms   []string
+}
+
+// Range matches the documents containing the index terms between From and To, the empty bound means the range is
+// open on that side. The terms are found by Expand
+type Range struct {
+	From, To               string
+	IncludeFrom, IncludeTo bool
+	Terms                  []string
+}
+
+// expander is the node matching the terms which are found in the term dictionary
+type expander interface {
+	expand(dict *index.Dictionary, limit int)
+}
+
+// Eval returns the documents containing any of the matched terms
+func (w *Wildcard) Eval(idx *index.Index) Set {
+	return evalTerms(idx, w.terms())
+}
+
+func (w *Wildcard) String() string {
+	return w.Pattern
+}
+
+func (w *Wildcard) walk(negated bool, weight float64, f visitor) {
+	for _, t := range w.terms() {
+		f(t, negated, weight)
+	}
+}
+
+// terms returns the matched terms or the pattern if no terms are matched, so it's reported as missing
+func (w *Wildcard) terms() []string {
+	if len(w.Terms) == 0 {
+		return []string{w.Pattern}
+	}
+	return w.Terms
+}
+
+func (w *Wildcard) expand(dict *index.Dictionary, limit int) {
+	w.Terms = truncate(dict.Wildcard(w.Pattern), limit)
+}
+
+// Eval returns the documents containing any of the matched terms
+func (r *Range) Eval(idx *index.Index) Set {
+	return evalTerms(idx, r.terms())
+}
+
+func (r *Range) String() string {
+	open, closing := "{", "}"
+	if r.IncludeFrom {
+		open = "["
+	}
+	if r.IncludeTo {
+		closing = "]"
+	}
+	return open + bound(r.From) + " TO " + bound(r.To) + closing
+}
+
+func (r *Range) walk(negated bool, weight float64, f visitor) {
+	for _, t := range r.terms() {
+		f(t, negated, weight)
+	}
+}
+
+// terms returns the matched terms or the range itself if no terms are matched, so it's reported as missing
+func (r *Range) terms() []string {
+	if len(r.Terms) == 0 {
+		return []string{r.String()}
+	}
+	return r.Terms
+}
+
+func (r *Range) expand(dict *index.Dictionary, limit int) {
+	r.Terms = truncate(dict.Range(r.From, r.To, r.IncludeFrom, r.IncludeTo), limit)
+}
+
+// bound returns the bound of the range in the query syntax
+func bound(b string) string {
+	if b == "" {
+		return "*"
+	}
+	return b
+}
+
+// evalTerms returns the documents containing any of the terms
+func evalTerms(idx *index.Index, terms []string) Set {
+	ans := make(Set)
+	for _, t := range terms {
+		for _, p := range idx.Terms[t] {
+			ans[p.DocID] = struct{}{}
+		}
+	}
+	return ans
+}
+
+// truncate returns at most limit first terms
+func truncate(terms []string, limit int) []string {
+	if len(terms) > limit {
+		return terms[:limit]
+	}
+	return terms
+}
+
+// Expand expands the fuzzy, wildcard and range terms of the query to at most limit terms of the dictionary each,
+// DefaultMaxExpansions is used if the limit isn't positive. It must be called before the terms of the query are read
+// from the store
+func Expand(n Node, dict *index.Dictionary, limit int) {
+	if limit <= 0 {
+		limit = DefaultMaxExpansions
+	}
+	visit(n, func(n Node) {
+		if e, ok := n.(expander); ok {
+			e.expand(dict, limit)
+		}
+	})
+}
+
+// visit calls f for every node of the query tree
+func visit(n Node, f func(n Node)) {
+	if n == nil {
+		return
+	}
+	f(n)
+
+	switch n := n.(type) {
+	case *And:
+		for _, c := range n.Nodes {
+			visit(c, f)
+		}
+	case *Or:
+		for _, c := range n.Nodes {
+			visit(c, f)
+		}
+	case *Not:
+		visit(n.Node, f)
+	case *Synonym:
+		visit(n.Node, f)
+	case *Filtered:
+		visit(n.Node, f)
+	case *Clauses:
+		for _, nodes := range [][]Node{n.Required, n.Optional, n.Prohibited} {
+			for _, c := range nodes {
+				visit(c, f)
+			}
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// Names of the document metadata fields the search is filtered by
+const (
+	FieldPath     = "path"
+	FieldExt      = "ext"
+	FieldModified = "modified"
+	FieldSize     = "size"
+)
+
+// Filter restricts the search to the documents with the matching metadata
+type Filter interface {
+	Match(doc *index.Document) bool
+	// String returns the filter in the query syntax
+	String() string
+}
+
+// Filtered matches the documents matching its node and all its filters. The filters are checked before the node is
+// evaluated, so the documents which don't match them are never scored. Nil node matches all the documents
+type Filtered struct {
+	Node    Node
+	Filters []Filter
+}
+
+// WithFilters returns the node restricted by the filters
+func WithFilters(n Node, filters []Filter) Node {
+	if len(filters) == 0 {
+		return n
+	}
+	if f, ok := n.(*Filtered); ok {
+		return &Filtered{Node: f.Node, Filters: append(append([]Filter{}, f.Filters...), filters...)}
+	}
+	return &Filtered{Node: n, Filters: filters}
+}
+
+// Eval returns the documents matching the filters and the node
+func (f *Filtered) Eval(idx *index.Index) Set {
+	allowed := make(Set)
+DocLoop:
+	for id, doc := range idx.Docs {
+		for _, filter := range f.Filters {
+			if !filter.Match(doc) {
+				continue DocLoop
+			}
+		}
+		allowed[id] = struct{}{}
+	}
+	if f.Node == nil || len(allowed) == 0 {
+		return allowed
+	}
+	return allowed.Intersect(f.Node.Eval(idx))
+}
+
+func (f *Filtered) String() string {
+	parts := make([]string, 0, len(f.Filters)+1)
+	if f.Node != nil {
+		parts = append(parts, f.Node.String())
+	}
+	for _, filter := range f.Filters {
+		parts = append(parts, filter.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func (f *Filtered) walk(negated bool, weight float64, v visitor) {
+	if f.Node != nil {
+		f.Node.walk(negated, weight, v)
+	}
+}
+
+// ParseFilter parses the value of the filter by the metadata field:
+//
+//	path:docs/*            - relative path or any of its parent directories matches the glob, '*' doesn't cross '/'
+//	ext:md,txt             - extension is any of the listed ones
+//	modified:>2026-01-01   - modification time compared with the date or the RFC 3339 time by >, >=, <, <= or =
+//	size:<=10kb            - size compared with the number of bytes, kb, mb or gb
+func ParseFilter(field, value string) (Filter, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: filter %s has no value", ErrSyntax, field)
+	}
+
+	switch field {
+	case FieldPath:
+		pattern := strings.Trim(value, "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid path pattern %q", ErrSyntax, value)
+		}
+		return &pathFilter{pattern: pattern}, nil
+
+	case FieldExt:
+		exts := make(map[string]struct{})
+		for _, e := range strings.Split(value, ",") {
+			if e = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(e), ".")); e != "" {
+				exts[e] = struct{}{}
+			}
+		}
+		if len(exts) == 0 {
+			return nil, fmt.Errorf("%w: filter %s has no extensions", ErrSyntax, field)
+		}
+		return &extFilter{exts: exts}, nil
+
+	case FieldModified:
+		return parseBound(field, value, parseTime, func(doc *index.Document) int64 {
+			return doc.ModTime.UnixNano()
+		})
+
+	case FieldSize:
+		return parseBound(field, value, parseSize, func(doc *index.Document) int64 {
+			return doc.Size
+		})
+
+	default:
+		return nil, fmt.Errorf("%w: unknown filter field %q", ErrSyntax, field)
+	}
+}
+
+// splitFilter splits the query word written as field:value into the field and the value, ok reports whether the field
+// is the known metadata field
+func splitFilter(word string) (field, value string, ok bool) {
+	i := strings.IndexByte(word, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	switch field = word[:i]; field {
+	case FieldPath, FieldExt, FieldModified, FieldSize:
+		return field, word[i+1:], true
+	default:
+		return "", "", false
+	}
+}
+
+// isFilter reports whether the query word is the metadata filter
+func isFilter(word string) bool {
+	_, _, ok := splitFilter(word)
+	return ok
+}
+
+// pathFilter matches the relative path and its parent directories against the glob
+type pathFilter struct {
+	pattern string
+}
+
+func (f *pathFilter) Match(doc *index.Document) bool {
+	p := doc.RelPath
+	for {
+		if ok, _ := path.Match(f.pattern, p); ok {
+			return true
+		}
+		i := strings.LastIndexByte(p, '/')
+		if i < 0 {
+			return false
+		}
+		p = p[:i]
+	}
+}
+
+func (f *pathFilter) String() string {
+	return FieldPath + ":" + f.pattern
+}
+
+// extFilter matches the documents with any of the extensions
+type extFilter struct {
+	exts map[string]struct{}
+}
+
+func (f *extFilter) Match(doc *index.Document) bool {
+	_, ok := f.exts[doc.Ext]
+	return ok
+}
+
+func (f *extFilter) String() string {
+	exts := make([]string, 0, len(f.exts))
+	for e := range f.exts {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	return FieldExt + ":" + strings.Join(exts, ",")
+}
+
+// boundFilter matches the documents whose numeric field is from the lower bound including it up to the upper bound
+// excluding it
+type boundFilter struct {
+	field    string
+	raw      string
+	from, to int64
+	value    func(doc *index.Document) int64
+}
+
+func (f *boundFilter) Match(doc *index.Document) bool {
+	v := f.value(doc)
+	return v >= f.from && v < f.to
+}
+
+func (f *boundFilter) String() string {
+	return f.field + ":" + f.raw
+}
+
+// parseBound parses the comparison of the field with the value. parse returns the interval of the field values which
+// are equal to the value, like all the times of the day for the date
+func parseBound(field, raw string, parse func(string) (start, end int64, err error),
+	value func(doc *index.Document) int64) (Filter, error) {
+
+	op, operand := "=", raw
+	for _, o := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(raw, o) {
+			op, operand = o, raw[len(o):]
+			break
+		}
+	}
+
+	start, end, err := parse(operand)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s filter %q: %v", ErrSyntax, field, raw, err)
+	}
+
+	f := &boundFilter{field: field, raw: raw, from: math.MinInt64, to: math.MaxInt64, value: value}
+	switch op {
+	case ">":
+		f.from = end
+	case ">=":
+		f.from = start
+	case "<":
+		f.to = start
+	case "<=":
+		f.to = end
+	default:
+		f.from, f.to = start, end
+	}
+	return f, nil
+}
+
+// parseTime returns the interval of the day of the date or the moment of the RFC 3339 time in unix nanoseconds
+func parseTime(s string) (start, end int64, err error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.UnixNano(), t.AddDate(0, 0, 1).UnixNano(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, 0, errors.New("time must be written as 2006-01-02 or 2006-01-02T15:04:05Z07:00")
+	}
+	return t.UnixNano(), t.UnixNano() + 1, nil
+}
+
+// sizeUnits are the multipliers of the size units
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30}, {"b", 1},
+}
+
+// parseSize returns the number of bytes of the size with the optional unit
+func parseSize(s string) (start, end int64, err error) {
+	s = strings.ToLower(s)
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSuffix(s, u.suffix), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/unit-1 {
+		return 0, 0, errors.New("size must be the number of bytes, kb, mb or gb")
+	}
+	return n * unit, n*unit + 1, nil
+}
+
+// notFilter matches the documents which don't match its filter
+type notFilter struct {
+	Filter
+}
+
+func (f *notFilter) Match(doc *index.Document) bool {
+	return !f.Filter.Match(doc)
+}
+
+func (f *notFilter) String() string {
+	return "-" + f.Filter.String()
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"strconv"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// AutoDistance is the distance of the typo-tolerant terms, it's chosen by the word length and the word is expanded
+// only if it isn't in the index
+const AutoDistance = -1
+
+// Fuzzy matches the documents containing the index terms within the edit distance of the word. The terms are found
+// by Expand, until then the node matches the word only
+type Fuzzy struct {
+	Word string
+	// Distance is the maximum edit distance or AutoDistance
+	Distance int
+	Matches  []index.Match
+}
+
+// Eval returns the documents containing any of the matched terms
+func (z *Fuzzy) Eval(idx *index.Index) Set {
+	ans := make(Set)
+	for _, t := range z.terms() {
+		for _, p := range idx.Terms[t.Term] {
+			ans[p.DocID] = struct{}{}
+		}
+	}
+	return ans
+}
+
+func (z *Fuzzy) String() string {
+	if z.Distance == AutoDistance {
+		return z.Word + "~"
+	}
+	return z.Word + "~" + strconv.Itoa(z.Distance)
+}
+
+// walk reports the matched terms, the farther the term is from the word the lower its weight
+func (z *Fuzzy) walk(negated bool, weight float64, f visitor) {
+	for _, t := range z.terms() {
+		f(t.Term, negated, weight/float64(1+t.Distance))
+	}
+}
+
+// terms returns the matched terms or the word itself if the node isn't expanded yet
+func (z *Fuzzy) terms() []index.Match {
+	if z.Matches == nil {
+		return []index.Match{{Term: z.Word}}
+	}
+	return z.Matches
+}
+
+// expand finds the terms of the dictionary matching the node
+func (z *Fuzzy) expand(dict *index.Dictionary, limit int) {
+	distance := z.Distance
+	if distance == AutoDistance {
+		if dict.Contains(z.Word) {
+			z.Matches = []index.Match{{Term: z.Word}}
+			return
+		}
+		distance = autoDistance(z.Word)
+	}
+
+	z.Matches = dict.Fuzzy(z.Word, distance)
+	if len(z.Matches) == 0 {
+		// the word is kept to be reported as missing
+		z.Matches = []index.Match{{Term: z.Word}}
+	} else if len(z.Matches) > limit {
+		z.Matches = z.Matches[:limit]
+	}
+}
+
+// autoDistance returns the edit distance tolerated in the word: none for the short words, one typo for the words up
+// to 5 letters and two typos for the longer ones
+func autoDistance(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return index.MaxDistance
+	}
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenRequired
+	tokenProhibited
+	tokenLParen
+	tokenRParen
+	tokenRange
+)
+
+// token is a lexical unit of the query
+type token struct {
+	kind  tokenKind
+	value string
+	// start and end are the offsets of the token in the runes of the query
+	start, end int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenPhrase:
+		return fmt.Sprintf("%q", t.value)
+	default:
+		return fmt.Sprintf("'%s'", t.value)
+	}
+}
+
+// lex splits the raw query into tokens. Operators AND, OR and NOT are recognized only in upper case, prefixes + and -
+// only right before a word, a phrase or a parenthesis
+func lex(input string) []token {
+
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", start: i, end: i + 1})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", start: i, end: i + 1})
+			i++
+
+		case c == '[' || c == '{':
+			// an unclosed range lasts until the end of the query
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' && runes[end] != '}' {
+				end++
+			}
+			if end < len(runes) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenRange, value: string(runes[i:end]), start: i, end: end})
+			i = end
+
+		case c == '"':
+			// an unclosed quote lasts until the end of the query
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			closing := end + 1
+			if closing > len(runes) {
+				closing = len(runes)
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, value: string(runes[i+1 : end]), start: i, end: closing})
+			i = end + 1
+
+		case (c == '+' || c == '-') && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			kind := tokenRequired
+			if c == '-' {
+				kind = tokenProhibited
+			}
+			tokens = append(tokens, token{kind: kind, value: string(c), start: i, end: i + 1})
+			i++
+
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"[{`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			kind := tokenWord
+			switch word {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, value: word, start: i, end: end})
+			i = end
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, start: len(runes), end: len(runes)})
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+// MissingWords returns the unique words of the query whose terms are missing, in the order of the query. The missing
+// terms are analyzed, so they are mapped back to the words the user typed. Only the plain words and the phrases are
+// checked, the missing terms of the synonyms aren't reported
+func MissingWords(input string, analyzer analysis.Analyzer, missing []string) ([]string, error) {
+	mw := newMissingWords(analyzer, missing)
+	for _, text := range plainTexts(input) {
+		if err := mw.add(text); err != nil {
+			return nil, err
+		}
+	}
+	return mw.words, nil
+}
+
+// ClauseMissingWords returns the unique words of the structured query whose terms are missing like MissingWords does
+func ClauseMissingWords(c *Clause, analyzer analysis.Analyzer, missing []string) ([]string, error) {
+	mw := newMissingWords(analyzer, missing)
+	var err error
+	c.visit(func(text string, query bool) {
+		if err != nil {
+			return
+		}
+		if !query {
+			err = mw.add(text)
+			return
+		}
+		for _, t := range plainTexts(text) {
+			if err = mw.add(t); err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mw.words, nil
+}
+
+// missingWords collects the unique words whose terms are missing
+type missingWords struct {
+	analyzer analysis.Analyzer
+	missing  map[string]struct{}
+	words    []string
+	seen     map[string]struct{}
+}
+
+func newMissingWords(analyzer analysis.Analyzer, missing []string) *missingWords {
+	mw := &missingWords{
+		analyzer: analyzer,
+		missing:  make(map[string]struct{}, len(missing)),
+		words:    []string{},
+		seen:     make(map[string]struct{}),
+	}
+	for _, term := range missing {
+		mw.missing[term] = struct{}{}
+	}
+	return mw
+}
+
+// add adds the words of the text whose terms are missing
+func (mw *missingWords) add(text string) error {
+	if len(mw.missing) == 0 {
+		return nil
+	}
+	terms, surfaces, err := analysis.AnalyzeSurfaces(mw.analyzer, text)
+	if err != nil {
+		return fmt.Errorf("error while analyzing words in query: %w", err)
+	}
+	for i, term := range terms {
+		if _, ok := mw.missing[term]; !ok {
+			continue
+		}
+		if _, ok := mw.seen[surfaces[i]]; !ok {
+			mw.seen[surfaces[i]] = struct{}{}
+			mw.words = append(mw.words, surfaces[i])
+		}
+	}
+	return nil
+}
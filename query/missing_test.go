@@ -0,0 +1,54 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+func TestMissingWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		missing []string
+		want    []string
+	}{
+		{name: "Words", input: "Xyzzy golang xyzzy", missing: []string{"xyzzi"}, want: []string{"xyzzy"}},
+		{name: "Word forms", input: "compared comparing", missing: []string{"compar"},
+			want: []string{"compared", "comparing"}},
+		{name: "Phrases", input: `"hello worlds" -java`, missing: []string{"world", "java"},
+			want: []string{"worlds", "java"}},
+		{name: "Special terms", input: "xyz* xyzzy~ [a TO xyzzy] ext:xyzzy", missing: []string{"xyzzi"},
+			want: []string{}},
+		{name: "Nothing is missing", input: "xyzzy", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MissingWords(tt.input, analysis.ForLanguage(analysis.English), tt.missing)
+			if err != nil {
+				t.Errorf("MissingWords() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClauseMissingWords(t *testing.T) {
+	c := &Clause{Or: []*Clause{
+		{Term: "xyzzy"},
+		{Not: &Clause{Phrase: "worlds of golang"}},
+		{Query: `"is it" AND comparing`},
+		{Wildcard: "xyzz*"},
+	}}
+	got, err := ClauseMissingWords(c, analysis.ForLanguage(analysis.English), []string{"xyzzi", "world", "compar"})
+	if err != nil {
+		t.Fatalf("ClauseMissingWords() error = %v", err)
+	}
+	if want := []string{"xyzzy", "worlds", "comparing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ClauseMissingWords() = %v, want %v", got, want)
+	}
+}
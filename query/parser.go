@@ -0,0 +1,424 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/polisgo2020/search-Arkronzxc/synonym"
+)
+
+// ErrSyntax is returned when the query can't be parsed
+var ErrSyntax = errors.New("query syntax error")
+
+// DefaultSynonymWeight is the default score weight of the synonyms relative to the words of the query
+const DefaultSynonymWeight = 0.5
+
+// Parser parses the queries with the analyzer and expands their words and phrases with the synonyms
+type Parser struct {
+	Analyzer analysis.Analyzer
+	// Settings are the analysis settings of the index which the analyzer is made by, the wildcard and range patterns
+	// are normalized by them. The zero settings normalize the patterns like the standard analyzer
+	Settings analysis.Settings
+	// Synonyms is the synonym dictionary, the queries aren't expanded if it's nil
+	Synonyms *synonym.Dictionary
+	// SynonymWeight is the score weight of the synonyms which are added to the query word, DefaultSynonymWeight is
+	// used if it isn't positive
+	SynonymWeight float64
+	// TypoTolerant makes every query word fuzzy with the distance chosen by its length, the word is expanded only if
+	// it isn't in the index
+	TypoTolerant bool
+}
+
+// Parse parses the raw user query. The grammar in the order of decreasing precedence is:
+//
+//	word, "phrase", (query)  - term, phrase or group
+//	word~N, word~            - fuzzy term within N edits or within the edits chosen by the word length
+//	wor*, w?rd               - wildcard term, '*' is any number of letters and '?' is one letter
+//	[from TO to], {from TO to} - range of terms including or excluding the bounds, '*' is the open bound
+//	field:value              - metadata filter of path, ext, modified or size, see ParseFilter
+//	+clause, -clause, NOT clause - required and prohibited clauses
+//	clause clause            - any clause matches unless some of them are required
+//	query AND query          - both queries match
+//	query OR query           - any query matches
+//
+// Words are analyzed by the analyzer the same way the documents are. Filters restrict the clauses written next to them:
+// "golang ext:md" finds golang in md files, while "golang OR ext:md" finds golang in any file or any md file. Filters
+// may be negated by '-' or NOT. Parse returns nil node if the query is empty or has no words to search and no filters
+func Parse(input string, analyzer analysis.Analyzer) (Node, error) {
+	return (&Parser{Analyzer: analyzer}).Parse(input)
+}
+
+// Parse parses the raw user query like the package Parse function does. Every word or phrase which has synonyms in
+// the dictionary is replaced by the OR of itself and its synonyms, or only by the synonyms if the rule is the explicit
+// mapping
+func (pp *Parser) Parse(input string) (Node, error) {
+	p := &parser{tokens: lex(input), Parser: pp}
+	if p.peek().kind == tokenEOF {
+		// the empty query finds nothing
+		return nil, nil
+	}
+
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected %s", ErrSyntax, p.peek())
+	}
+	return n, nil
+}
+
+type parser struct {
+	*Parser
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	var nodes []Node
+	for {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	default:
+		return &Or{Nodes: nodes}, nil
+	}
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for {
+		n, err := p.parseClauses()
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		if p.peek().kind != tokenAnd {
+			break
+		}
+		p.next()
+	}
+
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	default:
+		return &And{Nodes: nodes}, nil
+	}
+}
+
+// parseClauses parses the clauses between the operators restricted by the filters written among them
+func (p *parser) parseClauses() (Node, error) {
+	n, filters, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	return WithFilters(n, filters), nil
+}
+
+func (p *parser) parseGroup() (Node, []Filter, error) {
+	c := &Clauses{}
+	var filters []Filter
+	var parsed int
+
+ClauseLoop:
+	for {
+		kind := p.peek().kind
+		switch kind {
+		case tokenRequired, tokenProhibited, tokenNot:
+			p.next()
+		case tokenWord, tokenPhrase, tokenLParen, tokenRange:
+		default:
+			break ClauseLoop
+		}
+
+		if t := p.peek(); t.kind == tokenWord && isFilter(t.value) {
+			p.next()
+			field, value, _ := splitFilter(t.value)
+			f, err := ParseFilter(field, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			if kind == tokenProhibited || kind == tokenNot {
+				f = &notFilter{Filter: f}
+			}
+			filters = append(filters, f)
+			parsed++
+			continue
+		}
+
+		n, err := p.parsePrimary()
+		if err != nil {
+			return nil, nil, err
+		}
+		parsed++
+		if n == nil {
+			continue
+		}
+
+		switch kind {
+		case tokenRequired:
+			c.Required = append(c.Required, n)
+		case tokenProhibited, tokenNot:
+			c.Prohibited = append(c.Prohibited, n)
+		default:
+			c.Optional = append(c.Optional, n)
+		}
+	}
+
+	if parsed == 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected %s", ErrSyntax, p.peek())
+	}
+
+	switch {
+	case len(c.Required)+len(c.Optional)+len(c.Prohibited) == 0:
+		return nil, filters, nil
+	case len(c.Required) == 0 && len(c.Prohibited) == 0 && len(c.Optional) == 1:
+		return c.Optional[0], filters, nil
+	case len(c.Required) == 0 && len(c.Prohibited) == 0:
+		return &Or{Nodes: c.Optional}, filters, nil
+	case len(c.Optional) == 0 && len(c.Prohibited) == 0 && len(c.Required) == 1:
+		return c.Required[0], filters, nil
+	case len(c.Optional) == 0 && len(c.Prohibited) == 0:
+		return &And{Nodes: c.Required}, filters, nil
+	case len(c.Required) == 0 && len(c.Optional) == 0 && len(c.Prohibited) == 1:
+		return &Not{Node: c.Prohibited[0]}, filters, nil
+	default:
+		return c, filters, nil
+	}
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenWord:
+		if strings.ContainsAny(t.value, "*?") {
+			return p.wildcard(t.value), nil
+		}
+		word, distance, ok, err := fuzzySuffix(t.value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return p.fuzzy(word, distance)
+		}
+		return p.expand(t.value, true)
+
+	case tokenPhrase:
+		return p.expand(t.value, false)
+
+	case tokenRange:
+		return p.parseRange(t.value)
+
+	case tokenLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("%w: expected ')' instead of %s", ErrSyntax, closing)
+		}
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unexpected %s", ErrSyntax, t)
+	}
+}
+
+// expand returns the node of the query word or phrase together with its synonyms
+func (p *parser) expand(text string, word bool) (Node, error) {
+	synonyms, replace := p.Synonyms.Lookup(text)
+
+	var nodes []Node
+	if !replace {
+		words, positions, err := p.analyze(text)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, nil
+		}
+		if word && p.TypoTolerant && len(words) == 1 {
+			nodes = append(nodes, &Fuzzy{Word: words[0], Distance: AutoDistance})
+		} else {
+			nodes = append(nodes, wordsNode(words, positions))
+		}
+	}
+
+	for _, s := range synonyms {
+		words, positions, err := p.analyze(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			continue
+		}
+		n := wordsNode(words, positions)
+		if !replace {
+			n = &Synonym{Node: n, Weight: p.synonymWeight()}
+		}
+		nodes = append(nodes, n)
+	}
+
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	default:
+		return &Or{Nodes: nodes}, nil
+	}
+}
+
+// fuzzy returns the fuzzy term of the word, the text which is analyzed to several words is searched as a phrase
+func (p *parser) fuzzy(text string, distance int) (Node, error) {
+	words, positions, err := p.analyze(text)
+	if err != nil {
+		return nil, err
+	}
+	switch len(words) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &Fuzzy{Word: words[0], Distance: distance}, nil
+	default:
+		return wordsNode(words, positions), nil
+	}
+}
+
+// analyze returns the analyzed words of the text and their positions counted from the first word. The positions are
+// nil if no words are dropped between the words, otherwise the dropped words leave the gaps like in the documents
+func (p *parser) analyze(text string) (words []string, positions []int, err error) {
+	gapped, err := analysis.AnalyzeGaps(p.Analyzer, text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while analyzing words in query: %w", err)
+	}
+
+	gaps := false
+	for i, w := range gapped {
+		if w == "" {
+			continue
+		}
+		if len(positions) > 0 && i != positions[len(positions)-1]+1 {
+			gaps = true
+		}
+		words = append(words, w)
+		positions = append(positions, i)
+	}
+	if !gaps {
+		return words, nil, nil
+	}
+	first := positions[0]
+	for i := range positions {
+		positions[i] -= first
+	}
+	return words, positions, nil
+}
+
+// wordsNode returns the term or the phrase of the analyzed words at the positions
+func wordsNode(words []string, positions []int) Node {
+	if len(words) == 1 {
+		return &Term{Word: words[0]}
+	}
+	return &Phrase{Words: words, Positions: positions}
+}
+
+// wildcard returns the wildcard term of the word. Wildcard terms aren't analyzed, only normalized, so they match the
+// terms as they are stored in the index
+func (p *parser) wildcard(value string) Node {
+	pattern := p.Settings.NormalizePattern(value)
+	if pattern == "" {
+		return nil
+	}
+	return &Wildcard{Pattern: pattern}
+}
+
+// parseRange parses the range written as [from TO to] or {from TO to}, square brackets include the bound and curly
+// brackets exclude it
+func (p *parser) parseRange(value string) (Node, error) {
+	runes := []rune(value)
+	if len(runes) < 2 || (runes[len(runes)-1] != ']' && runes[len(runes)-1] != '}') {
+		return nil, fmt.Errorf("%w: unclosed range %s", ErrSyntax, value)
+	}
+
+	bounds := strings.Fields(string(runes[1 : len(runes)-1]))
+	if len(bounds) != 3 || bounds[1] != "TO" {
+		return nil, fmt.Errorf("%w: range %s must be written as [from TO to]", ErrSyntax, value)
+	}
+
+	r := &Range{
+		IncludeFrom: runes[0] == '[',
+		IncludeTo:   runes[len(runes)-1] == ']',
+	}
+	if bounds[0] != "*" {
+		r.From = p.Settings.NormalizePattern(bounds[0])
+	}
+	if bounds[2] != "*" {
+		r.To = p.Settings.NormalizePattern(bounds[2])
+	}
+	return r, nil
+}
+
+// fuzzySuffix splits the word written as word~ or word~N into the word and the edit distance, ok reports whether the
+// word has the suffix
+func fuzzySuffix(value string) (word string, distance int, ok bool, err error) {
+	i := strings.LastIndexByte(value, '~')
+	if i < 0 {
+		return value, 0, false, nil
+	}
+	word, suffix := value[:i], value[i+1:]
+	if suffix == "" {
+		return word, AutoDistance, true, nil
+	}
+	distance, convErr := strconv.Atoi(suffix)
+	if convErr != nil {
+		return value, 0, false, nil
+	}
+	if distance < 0 || distance > index.MaxDistance {
+		return "", 0, false, fmt.Errorf("%w: edit distance of '%s' must be from 0 to %d", ErrSyntax, value,
+			index.MaxDistance)
+	}
+	return word, distance, true, nil
+}
+
+func (p *parser) synonymWeight() float64 {
+	if p.SynonymWeight <= 0 {
+		return DefaultSynonymWeight
+	}
+	return p.SynonymWeight
+}
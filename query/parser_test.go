@@ -0,0 +1,293 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/synonym"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Implicit or",
+			input: "hello world",
+			want:  "(hello OR world)",
+		},
+		{
+			name:  "Stop words and stemming",
+			input: "the Freezing of world",
+			want:  "(freez OR world)",
+		},
+		{
+			name:  "And binds tighter than or",
+			input: "hello AND world OR golang",
+			want:  "((hello AND world) OR golang)",
+		},
+		{
+			name:  "Parentheses",
+			input: "hello AND (world OR golang)",
+			want:  "(hello AND (world OR golang))",
+		},
+		{
+			name:  "Required and prohibited",
+			input: "+hello world -java",
+			want:  "(+hello world -java)",
+		},
+		{
+			name:  "Not operator",
+			input: "hello NOT java",
+			want:  "(hello -java)",
+		},
+		{
+			name:  "Single negation",
+			input: "NOT java",
+			want:  "NOT java",
+		},
+		{
+			name:  "Phrase",
+			input: `"inverted index" golang`,
+			want:  `("invert index" OR golang)`,
+		},
+		{
+			name:  "Unclosed phrase",
+			input: `golang "inverted index`,
+			want:  `(golang OR "invert index")`,
+		},
+		{
+			name:  "Empty query",
+			input: "",
+			want:  "",
+		},
+		{
+			name:  "Only whitespace",
+			input: " \t\n",
+			want:  "",
+		},
+		{
+			name:  "Only stop words",
+			input: "the and of",
+			want:  "",
+		},
+		{
+			name:  "Wildcards aren't analyzed",
+			input: "Optim* co?e",
+			want:  "(optim* OR co?e)",
+		},
+		{
+			name:  "Ranges",
+			input: "[apple TO Cherry] -{* TO banana}",
+			want:  "([apple TO cherry] -{* TO banana})",
+		},
+		{
+			name:    "Range without TO",
+			input:   "[apple cherry]",
+			wantErr: true,
+		},
+		{
+			name:    "Unclosed range",
+			input:   "[apple TO cherry",
+			wantErr: true,
+		},
+		{
+			name:    "Unbalanced parenthesis",
+			input:   "(hello world",
+			wantErr: true,
+		},
+		{
+			name:    "Extra parenthesis",
+			input:   "hello world)",
+			wantErr: true,
+		},
+		{
+			name:    "Dangling operator",
+			input:   "hello AND",
+			wantErr: true,
+		},
+		{
+			name:    "Leading operator",
+			input:   "OR hello",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, analysis.ForLanguage(analysis.English))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Parse() error = %v, want ErrSyntax", err)
+				}
+				return
+			}
+			var gotString string
+			if got != nil {
+				gotString = got.String()
+			}
+			if gotString != tt.want {
+				t.Errorf("Parse() got = %v, want %v", gotString, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	got, err := Parse("книги и журналы", analysis.ForLanguage(analysis.Russian))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := "(книг OR журнал)"; got.String() != want {
+		t.Errorf("Parse() got = %v, want %v", got.String(), want)
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings analysis.Settings
+		input    string
+		want     string
+	}{
+		{name: "Stemming", settings: analysis.DefaultSettings(), input: "Go-1* [A1 TO b2]", want: "(go* OR [a TO b])"},
+		{name: "Whitespace", settings: analysis.Settings{Analyzer: analysis.Whitespace}, input: "Go-1* [A1 TO b2]",
+			want: "(Go-1* OR [A1 TO b2])"},
+		{name: "Keyword", settings: analysis.Settings{Analyzer: analysis.Keyword}, input: "v1.?", want: "v1.?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer, err := tt.settings.New(analysis.English)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got, err := (&Parser{Analyzer: analyzer, Settings: tt.settings}).Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSynonyms(t *testing.T) {
+	synonyms, err := synonym.Parse(strings.NewReader("car, automobile\ntv => television set"))
+	if err != nil {
+		t.Fatalf("synonym.Parse() error = %v", err)
+	}
+	p := &Parser{
+		Analyzer:      analysis.ForLanguage(analysis.English),
+		Synonyms:      synonyms,
+		SynonymWeight: 0.25,
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "cars", want: "car"},
+		{input: "car", want: "(car OR automobil^0.25)"},
+		{input: "+car -truck", want: "(+(car OR automobil^0.25) -truck)"},
+		{input: "TV", want: `"televis set"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFuzzy(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		typoTolerant bool
+		want         string
+		wantErr      bool
+	}{
+		{name: "Edit distance", input: "golnag~2 world", want: "(golnag~2 OR world)"},
+		{name: "Auto distance", input: "golnag~", want: "golnag~"},
+		{name: "Analyzed word", input: "Freezing~1", want: "freez~1"},
+		{name: "Phrase proximity is ignored", input: `"hello world"~2`, want: `"hello world"`},
+		{name: "Typo tolerant", input: `hello "big world" -java~1`, typoTolerant: true,
+			want: `(hello~ "big world" -java~1)`},
+		{name: "Too large distance", input: "golang~3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{Analyzer: analysis.ForLanguage(analysis.English), TypoTolerant: tt.typoTolerant}
+			got, err := p.Parse(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Parse() error = %v, want ErrSyntax", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Filters restrict the query",
+			input: "golang path:docs/* ext:.MD,txt",
+			want:  "(golang path:docs/* ext:md,txt)",
+		},
+		{name: "Negated filters", input: "golang -ext:md NOT size:>1kb", want: "(golang -ext:md -size:>1kb)"},
+		{name: "Only filters", input: "modified:>=2026-01-01", want: "(modified:>=2026-01-01)"},
+		{name: "Filters in groups", input: "java AND (golang OR ext:md)", want: "(java AND (golang OR (ext:md)))"},
+		{name: "Filters in OR", input: "hello OR ext:md", want: "(hello OR (ext:md))"},
+		{name: "Filters in parentheses", input: "(hello ext:md) OR world", want: "((hello ext:md) OR world)"},
+		{name: "Filters of the group", input: "(hello OR world) ext:md", want: "((hello OR world) ext:md)"},
+		{name: "Unknown fields are words", input: "title:golang", want: `"titl golang"`},
+		{name: "Invalid date", input: "modified:>yesterday", wantErr: true},
+		{name: "Invalid size", input: "size:<10tb", wantErr: true},
+		{name: "Invalid pattern", input: "path:[docs", wantErr: true},
+		{name: "Empty value", input: "ext:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, analysis.ForLanguage(analysis.English))
+			if tt.wantErr {
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Parse() error = %v, want ErrSyntax", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse() got = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
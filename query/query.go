@@ -0,0 +1,336 @@
+package query
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// Set is the set of the document IDs
+type Set map[int]struct{}
+
+// Node is the node of the parsed query tree
+type Node interface {
+	// Eval returns the documents of the index matching the node
+	Eval(idx *index.Index) Set
+	// String returns the normalized query of the node
+	String() string
+	// walk calls f for every term of the node, negated reports whether the term is under negation, weight is the
+	// score weight of the term
+	walk(negated bool, weight float64, f visitor)
+}
+
+// visitor is called for every term of the query tree
+type visitor func(term string, negated bool, weight float64)
+
+// Term matches the documents containing the cleaned word
+type Term struct {
+	Word string
+}
+
+// Phrase matches the documents containing the cleaned words in the same order at the same distances
+type Phrase struct {
+	Words []string
+	// Positions are the positions of the words in the phrase counting the dropped words like the stop words, the
+	// words are consecutive if it's nil
+	Positions []int
+}
+
+// And matches the documents matching all of its nodes
+type And struct {
+	Nodes []Node
+}
+
+// Or matches the documents matching any of its nodes
+type Or struct {
+	Nodes []Node
+}
+
+// Not matches the documents of the index which don't match its node
+type Not struct {
+	Node Node
+}
+
+// Synonym matches the documents matching its node. It's the expansion of the query word, so its terms are scored with
+// the lower weight than the words of the query
+type Synonym struct {
+	Node   Node
+	Weight float64
+}
+
+// Eval returns the documents containing the word
+func (t *Term) Eval(idx *index.Index) Set {
+	ans := make(Set)
+	for _, p := range idx.Terms[t.Word] {
+		ans[p.DocID] = struct{}{}
+	}
+	return ans
+}
+
+func (t *Term) String() string {
+	return t.Word
+}
+
+func (t *Term) walk(negated bool, weight float64, f visitor) {
+	f(t.Word, negated, weight)
+}
+
+// Eval returns the documents containing the phrase
+func (p *Phrase) Eval(idx *index.Index) Set {
+	ans := make(Set)
+	for id := range idx.MatchPhrase(p.Words, p.Positions) {
+		ans[id] = struct{}{}
+	}
+	return ans
+}
+
+func (p *Phrase) String() string {
+	return `"` + strings.Join(p.Words, " ") + `"`
+}
+
+func (p *Phrase) walk(negated bool, weight float64, f visitor) {
+	for _, w := range p.Words {
+		f(w, negated, weight)
+	}
+}
+
+// Eval returns the documents matching the node of the synonym
+func (s *Synonym) Eval(idx *index.Index) Set {
+	return s.Node.Eval(idx)
+}
+
+func (s *Synonym) String() string {
+	return s.Node.String() + "^" + strconv.FormatFloat(s.Weight, 'g', -1, 64)
+}
+
+func (s *Synonym) walk(negated bool, weight float64, f visitor) {
+	s.Node.walk(negated, weight*s.Weight, f)
+}
+
+// Eval returns the intersection of the documents of the nodes
+func (a *And) Eval(idx *index.Index) Set {
+	if len(a.Nodes) == 0 {
+		return make(Set)
+	}
+	ans := a.Nodes[0].Eval(idx)
+	for _, n := range a.Nodes[1:] {
+		ans = ans.Intersect(n.Eval(idx))
+	}
+	return ans
+}
+
+func (a *And) String() string {
+	return "(" + join(a.Nodes, " AND ") + ")"
+}
+
+func (a *And) walk(negated bool, weight float64, f visitor) {
+	for _, n := range a.Nodes {
+		n.walk(negated, weight, f)
+	}
+}
+
+// Eval returns the union of the documents of the nodes
+func (o *Or) Eval(idx *index.Index) Set {
+	ans := make(Set)
+	for _, n := range o.Nodes {
+		ans = ans.Union(n.Eval(idx))
+	}
+	return ans
+}
+
+func (o *Or) String() string {
+	return "(" + join(o.Nodes, " OR ") + ")"
+}
+
+func (o *Or) walk(negated bool, weight float64, f visitor) {
+	for _, n := range o.Nodes {
+		n.walk(negated, weight, f)
+	}
+}
+
+// Eval returns the difference between all the documents of the index and the documents of the node
+func (n *Not) Eval(idx *index.Index) Set {
+	all := make(Set, len(idx.Docs))
+	for id := range idx.Docs {
+		all[id] = struct{}{}
+	}
+	return all.Difference(n.Node.Eval(idx))
+}
+
+func (n *Not) String() string {
+	return "NOT " + n.Node.String()
+}
+
+func (n *Not) walk(negated bool, weight float64, f visitor) {
+	n.Node.walk(!negated, weight, f)
+}
+
+// Clauses is the list of the query clauses written one after another. It matches the documents matching all the
+// required nodes, or any of the optional nodes if there are no required ones, and none of the prohibited nodes
+type Clauses struct {
+	Required   []Node
+	Optional   []Node
+	Prohibited []Node
+}
+
+// Eval returns the documents matching the clauses
+func (c *Clauses) Eval(idx *index.Index) Set {
+	var ans Set
+	switch {
+	case len(c.Required) > 0:
+		ans = (&And{Nodes: c.Required}).Eval(idx)
+	case len(c.Optional) > 0:
+		ans = (&Or{Nodes: c.Optional}).Eval(idx)
+	default:
+		ans = make(Set, len(idx.Docs))
+		for id := range idx.Docs {
+			ans[id] = struct{}{}
+		}
+	}
+	for _, n := range c.Prohibited {
+		ans = ans.Difference(n.Eval(idx))
+	}
+	return ans
+}
+
+func (c *Clauses) String() string {
+	parts := make([]string, 0, len(c.Required)+len(c.Optional)+len(c.Prohibited))
+	for _, n := range c.Required {
+		parts = append(parts, "+"+n.String())
+	}
+	for _, n := range c.Optional {
+		parts = append(parts, n.String())
+	}
+	for _, n := range c.Prohibited {
+		parts = append(parts, "-"+n.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func (c *Clauses) walk(negated bool, weight float64, f visitor) {
+	for _, n := range c.Required {
+		n.walk(negated, weight, f)
+	}
+	for _, n := range c.Optional {
+		n.walk(negated, weight, f)
+	}
+	for _, n := range c.Prohibited {
+		n.walk(!negated, weight, f)
+	}
+}
+
+// Terms returns the sorted unique terms of the query which must be fetched to evaluate it
+func Terms(n Node) []string {
+	return collect(n, func(bool) bool { return true })
+}
+
+// NeedsAllDocuments reports whether the query may match the documents without its terms, like NOT or the filters do,
+// so it must be evaluated on the whole document table
+func NeedsAllDocuments(n Node) bool {
+	switch n := n.(type) {
+	case *Not, *Filtered:
+		return true
+	case *Synonym:
+		return NeedsAllDocuments(n.Node)
+	case *And:
+		return anyNeedsAllDocuments(n.Nodes)
+	case *Or:
+		return anyNeedsAllDocuments(n.Nodes)
+	case *Clauses:
+		return len(n.Required)+len(n.Optional) == 0 || anyNeedsAllDocuments(n.Required) ||
+			anyNeedsAllDocuments(n.Optional) || anyNeedsAllDocuments(n.Prohibited)
+	default:
+		return false
+	}
+}
+
+func anyNeedsAllDocuments(nodes []Node) bool {
+	for _, n := range nodes {
+		if NeedsAllDocuments(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// PositiveTerms returns the sorted unique terms of the query which aren't negated, they are used to rank the documents
+func PositiveTerms(n Node) []string {
+	return collect(n, func(negated bool) bool { return !negated })
+}
+
+// Weights returns the terms of the query which aren't negated with their score weights. The term which occurs in the
+// query several times gets the highest of its weights
+func Weights(n Node) map[string]float64 {
+	ans := make(map[string]float64)
+	if n == nil {
+		return ans
+	}
+	n.walk(false, 1, func(term string, negated bool, weight float64) {
+		if !negated && weight > ans[term] {
+			ans[term] = weight
+		}
+	})
+	return ans
+}
+
+func collect(n Node, filter func(negated bool) bool) []string {
+	if n == nil {
+		return nil
+	}
+	unique := make(map[string]struct{})
+	n.walk(false, 1, func(term string, negated bool, _ float64) {
+		if filter(negated) {
+			unique[term] = struct{}{}
+		}
+	})
+	ans := make([]string, 0, len(unique))
+	for t := range unique {
+		ans = append(ans, t)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+func join(nodes []Node, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, n.String())
+	}
+	return strings.Join(parts, sep)
+}
+
+// Union returns the documents which are in any of the sets
+func (s Set) Union(other Set) Set {
+	ans := make(Set, len(s)+len(other))
+	for k := range s {
+		ans[k] = struct{}{}
+	}
+	for k := range other {
+		ans[k] = struct{}{}
+	}
+	return ans
+}
+
+// Intersect returns the documents which are in both sets
+func (s Set) Intersect(other Set) Set {
+	ans := make(Set)
+	for k := range s {
+		if _, ok := other[k]; ok {
+			ans[k] = struct{}{}
+		}
+	}
+	return ans
+}
+
+// Difference returns the documents of the set which aren't in the other set
+func (s Set) Difference(other Set) Set {
+	ans := make(Set)
+	for k := range s {
+		if _, ok := other[k]; !ok {
+			ans[k] = struct{}{}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,299 @@
+package query
+
+import (
+	"container/heap"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+// ErrCursor is returned when the cursor can't be decoded or is made in another order
+var ErrCursor = errors.New("invalid cursor")
+
+// ErrSort is returned when the order of the hits is invalid
+var ErrSort = errors.New("invalid sort")
+
+// Hit is the document matching the query
+type Hit struct {
+	Document *index.Document
+	// WordsEncountered is the number of the query terms which aren't negated found in the document
+	WordsEncountered int
+	Score            float64
+}
+
+// Page is the part of the ordered hits. The hits after the cursor are skipped by offset and cut to the limit
+type Page struct {
+	// Limit is the maximum number of the hits, all the hits are returned if it isn't positive
+	Limit  int
+	Offset int
+	// After is the cursor of the last hit of the previous page, the page starts from the beginning if it's nil
+	After *Cursor
+	// Sort is the order of the hits, the zero value is DefaultSort
+	Sort Sort
+}
+
+// Fields the hits are sorted by
+const (
+	SortScore    = "score"
+	SortFilename = "filename"
+	SortMtime    = "mtime"
+	SortSize     = "size"
+)
+
+// Sort is the order of the hits by the field. The hits with the equal values of the field are ordered by the score
+// from the highest and then by the path, so the order of the same hits is always the same
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// DefaultSort orders the hits by the score from the highest
+var DefaultSort = Sort{Field: SortScore, Desc: true}
+
+// ParseSort parses the order written as field, field:asc or field:desc. Unless the direction is set, the hits are
+// ordered by the score and the modification time from the highest and by the filename and the size from the lowest.
+// The empty string is DefaultSort
+func ParseSort(s string) (Sort, error) {
+	if s == "" {
+		return DefaultSort, nil
+	}
+
+	field, dir := s, ""
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		field, dir = s[:i], s[i+1:]
+	}
+
+	var ans Sort
+	switch field {
+	case SortScore, SortMtime:
+		ans = Sort{Field: field, Desc: true}
+	case SortFilename, SortSize:
+		ans = Sort{Field: field}
+	default:
+		return Sort{}, fmt.Errorf("%w: unknown field %q, it must be one of %s, %s, %s, %s", ErrSort, field,
+			SortScore, SortFilename, SortMtime, SortSize)
+	}
+
+	switch dir {
+	case "":
+	case "asc":
+		ans.Desc = false
+	case "desc":
+		ans.Desc = true
+	default:
+		return Sort{}, fmt.Errorf("%w: unknown direction %q, it must be asc or desc", ErrSort, dir)
+	}
+	return ans, nil
+}
+
+// String returns the order written as field:asc or field:desc
+func (s Sort) String() string {
+	s = s.orDefault()
+	if s.Desc {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+func (s Sort) orDefault() Sort {
+	if s.Field == "" {
+		return DefaultSort
+	}
+	return s
+}
+
+// before reports whether the hit a goes before b in the order
+func (s Sort) before(a, b *Hit) bool {
+	s = s.orDefault()
+
+	da, db := a.Document, b.Document
+	switch {
+	case s.Field == SortFilename && da.Path != db.Path:
+		return (da.Path < db.Path) != s.Desc
+	case s.Field == SortMtime && !da.ModTime.Equal(db.ModTime):
+		return da.ModTime.Before(db.ModTime) != s.Desc
+	case s.Field == SortSize && da.Size != db.Size:
+		return (da.Size < db.Size) != s.Desc
+	case s.Field == SortScore && a.Score != b.Score:
+		return (a.Score < b.Score) != s.Desc
+	}
+
+	// ties are broken the same way in any order
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return da.Path < db.Path
+}
+
+// Cursor is the position of the hit in the order of the hits, it keeps all the values the hits are compared by
+type Cursor struct {
+	Score float64 `json:"s"`
+	Path  string  `json:"p"`
+	// Sort is the order the cursor is made in, the empty one is DefaultSort
+	Sort    string `json:"o,omitempty"`
+	Size    int64  `json:"z,omitempty"`
+	ModTime int64  `json:"m,omitempty"`
+}
+
+// CursorOf returns the cursor of the hit in the order
+func CursorOf(h *Hit, s Sort) *Cursor {
+	c := &Cursor{Score: h.Score, Path: h.Document.Path}
+	if s.orDefault() != DefaultSort {
+		c.Sort = s.String()
+		c.Size = h.Document.Size
+		c.ModTime = h.Document.ModTime.UnixNano()
+	}
+	return c
+}
+
+// Check returns ErrCursor if the cursor is made in another order than s, so the page can't skip or repeat the hits
+func (c *Cursor) Check(s Sort) error {
+	order := c.Sort
+	if order == "" {
+		order = DefaultSort.String()
+	}
+	if order != s.String() {
+		return fmt.Errorf("%w: it's made for sort %s, not %s", ErrCursor, order, s)
+	}
+	return nil
+}
+
+// hit returns the hit at the position of the cursor
+func (c *Cursor) hit() *Hit {
+	return &Hit{Score: c.Score, Document: &index.Document{
+		Path:    c.Path,
+		Size:    c.Size,
+		ModTime: time.Unix(0, c.ModTime),
+	}}
+}
+
+// String returns the opaque URL-safe representation of the cursor
+func (c *Cursor) String() string {
+	// the cursor consists of numbers and strings, so it's always encoded
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes the cursor returned by String
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCursor, err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCursor, err)
+	}
+	return &c, nil
+}
+
+// Search evaluates the query against the index and returns the matching documents ordered by BM25 score. Documents
+// with equal score are ordered by path to keep the result stable
+func Search(idx *index.Index, n Node, ranking index.BM25) []*Hit {
+	hits, _ := SearchPage(idx, n, ranking, Page{})
+	return hits
+}
+
+// SearchPage returns the page of the hits in the order of the page and the total number of the matching documents.
+// Only the hits up to the end of the page are ordered, they are selected with a heap
+func SearchPage(idx *index.Index, n Node, ranking index.BM25, page Page) ([]*Hit, int) {
+
+	if n == nil {
+		return nil, 0
+	}
+
+	// only the matched documents are scored, so the documents excluded by the filters never are
+	matched := n.Eval(idx)
+	terms := PositiveTerms(n)
+	scores := idx.WeightedScoreOf(Weights(n), ranking, matched)
+
+	encountered := make(map[int]int, len(matched))
+	for _, t := range terms {
+		for _, p := range idx.Terms[t] {
+			if _, ok := matched[p.DocID]; ok {
+				encountered[p.DocID]++
+			}
+		}
+	}
+
+	k := -1
+	if page.Limit > 0 {
+		k = page.Offset + page.Limit
+	}
+	top := &hitHeap{before: page.Sort.before}
+
+	var after *Hit
+	if page.After != nil {
+		after = page.After.hit()
+	}
+
+	var total int
+	for id := range matched {
+		doc, ok := idx.Docs[id]
+		if !ok {
+			continue
+		}
+		total++
+
+		h := &Hit{
+			Document:         doc,
+			WordsEncountered: encountered[id],
+			Score:            scores[id],
+		}
+		if after != nil && !top.before(after, h) {
+			continue
+		}
+		switch {
+		case k < 0 || top.Len() < k:
+			heap.Push(top, h)
+		case top.before(h, top.hits[0]):
+			top.hits[0] = h
+			heap.Fix(top, 0)
+		}
+	}
+
+	hits := top.hits
+	sort.Slice(hits, func(i, j int) bool {
+		return top.before(hits[i], hits[j])
+	})
+
+	if page.Offset >= len(hits) {
+		return []*Hit{}, total
+	}
+	return hits[page.Offset:], total
+}
+
+// hitHeap keeps the worst of the best hits on the top, so it's replaced when a better hit is found
+type hitHeap struct {
+	hits   []*Hit
+	before func(a, b *Hit) bool
+}
+
+func (h *hitHeap) Len() int {
+	return len(h.hits)
+}
+
+func (h *hitHeap) Less(i, j int) bool {
+	return h.before(h.hits[j], h.hits[i])
+}
+
+func (h *hitHeap) Swap(i, j int) {
+	h.hits[i], h.hits[j] = h.hits[j], h.hits[i]
+}
+
+func (h *hitHeap) Push(x interface{}) {
+	h.hits = append(h.hits, x.(*Hit))
+}
+
+func (h *hitHeap) Pop() interface{} {
+	old := h.hits
+	x := old[len(old)-1]
+	h.hits = old[:len(old)-1]
+	return x
+}
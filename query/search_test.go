@@ -0,0 +1,361 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/polisgo2020/search-Arkronzxc/synonym"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type searchTestSuite struct {
+	suite.Suite
+	index   *index.Index
+	ranking index.BM25
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(searchTestSuite))
+}
+
+func (f *searchTestSuite) SetupTest() {
+	f.ranking = index.BM25{K1: index.DefaultK1, B: index.DefaultB}
+	f.index = index.NewIndex()
+	// file1: "hello world java", file2: "hello golang", file3: "world golang", file4: "golang world"
+	f.index.Docs = map[int]*index.Document{
+		1: {ID: 1, Path: "file1", Length: 3},
+		2: {ID: 2, Path: "file2", Length: 2},
+		3: {ID: 3, Path: "file3", Length: 2},
+		4: {ID: 4, Path: "file4", Length: 2},
+	}
+	f.index.Terms["hello"] = index.Postings{
+		{DocID: 1, Freq: 1, Positions: []int{0}},
+		{DocID: 2, Freq: 1, Positions: []int{0}},
+	}
+	f.index.Terms["world"] = index.Postings{
+		{DocID: 1, Freq: 1, Positions: []int{1}},
+		{DocID: 3, Freq: 1, Positions: []int{0}},
+		{DocID: 4, Freq: 1, Positions: []int{1}},
+	}
+	f.index.Terms["java"] = index.Postings{
+		{DocID: 1, Freq: 1, Positions: []int{2}},
+	}
+	f.index.Terms["golang"] = index.Postings{
+		{DocID: 2, Freq: 1, Positions: []int{1}},
+		{DocID: 3, Freq: 1, Positions: []int{1}},
+		{DocID: 4, Freq: 1, Positions: []int{0}},
+	}
+}
+
+func (f *searchTestSuite) filenames(input string) []string {
+	n, err := Parse(input, analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	return f.filenamesOf(n)
+}
+
+func (f *searchTestSuite) filenamesOf(n Node) []string {
+	var ans []string
+	for _, h := range Search(f.index, n, f.ranking) {
+		ans = append(ans, h.Document.Path)
+	}
+	return ans
+}
+
+func (f *searchTestSuite) TestOr() {
+	require.ElementsMatch(f.T(), []string{"file1", "file2", "file3", "file4"}, f.filenames("hello OR golang"))
+}
+
+func (f *searchTestSuite) TestAnd() {
+	require.ElementsMatch(f.T(), []string{"file3", "file4"}, f.filenames("world AND golang"))
+}
+
+func (f *searchTestSuite) TestNot() {
+	require.ElementsMatch(f.T(), []string{"file3", "file4"}, f.filenames("world NOT java"))
+	require.ElementsMatch(f.T(), []string{"file3", "file4"}, f.filenames("world -java"))
+	require.ElementsMatch(f.T(), []string{"file2", "file3", "file4"}, f.filenames("NOT java"))
+}
+
+func (f *searchTestSuite) TestRequired() {
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, f.filenames("+hello world"))
+}
+
+func (f *searchTestSuite) TestGrouping() {
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, f.filenames("hello AND (world OR golang)"))
+}
+
+func (f *searchTestSuite) TestPhrase() {
+	require.ElementsMatch(f.T(), []string{"file3"}, f.filenames(`"world golang"`))
+}
+
+func (f *searchTestSuite) TestRanking() {
+	n, err := Parse("hello world", analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	hits := Search(f.index, n, f.ranking)
+	require.Len(f.T(), hits, 4)
+	require.Equal(f.T(), "file1", hits[0].Document.Path)
+	require.Equal(f.T(), 2, hits[0].WordsEncountered)
+}
+
+func (f *searchTestSuite) TestNeedsAllDocuments() {
+	for input, want := range map[string]bool{
+		"hello world":              false,
+		"world -java":              false,
+		"+hello (world OR go*)":    false,
+		"NOT java":                 true,
+		"-java -hello":             true,
+		"hello AND (world ext:md)": true,
+		"hello -(NOT java)":        true,
+	} {
+		n, err := Parse(input, analysis.ForLanguage(analysis.English))
+		require.NoError(f.T(), err)
+		require.Equal(f.T(), want, NeedsAllDocuments(n), input)
+	}
+}
+
+func (f *searchTestSuite) TestNegatedTermsAreNotCounted() {
+	n, err := Parse("world -java", analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"java", "world"}, Terms(n))
+	require.Equal(f.T(), []string{"world"}, PositiveTerms(n))
+	for _, h := range Search(f.index, n, f.ranking) {
+		require.Equal(f.T(), 1, h.WordsEncountered)
+	}
+}
+
+func (f *searchTestSuite) TestSynonymsScoreLower() {
+	synonyms, err := synonym.Parse(strings.NewReader("java, golang"))
+	require.NoError(f.T(), err)
+	p := &Parser{Analyzer: analysis.ForLanguage(analysis.English), Synonyms: synonyms}
+
+	n, err := p.Parse("java")
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), map[string]float64{"java": 1, "golang": DefaultSynonymWeight}, Weights(n))
+
+	hits := Search(f.index, n, f.ranking)
+	require.Len(f.T(), hits, 4)
+	require.Equal(f.T(), "file1", hits[0].Document.Path)
+}
+
+func (f *searchTestSuite) TestFuzzy() {
+	p := &Parser{Analyzer: analysis.ForLanguage(analysis.English), TypoTolerant: true}
+	dict := f.index.Dictionary()
+
+	n, err := p.Parse("golnag")
+	require.NoError(f.T(), err)
+	Expand(n, dict, 0)
+	require.Equal(f.T(), map[string]float64{"golang": 1.0 / 3}, Weights(n))
+	require.Len(f.T(), Search(f.index, n, f.ranking), 3)
+
+	n, err = p.Parse("world")
+	require.NoError(f.T(), err)
+	Expand(n, dict, 0)
+	require.Equal(f.T(), []string{"world"}, Terms(n), "known word must not be expanded")
+
+	n, err = p.Parse("hava~1")
+	require.NoError(f.T(), err)
+	Expand(n, dict, 0)
+	require.ElementsMatch(f.T(), []string{"file1"}, f.filenamesOf(n))
+
+	n, err = p.Parse("rust")
+	require.NoError(f.T(), err)
+	Expand(n, dict, 0)
+	require.Equal(f.T(), []string{"rust"}, Terms(n), "unknown word must be reported as missing")
+}
+
+func (f *searchTestSuite) TestWildcardAndRange() {
+	dict := f.index.Dictionary()
+	search := func(input string, limit int) []string {
+		n, err := Parse(input, analysis.ForLanguage(analysis.English))
+		require.NoError(f.T(), err)
+		Expand(n, dict, limit)
+		return f.filenamesOf(n)
+	}
+
+	require.ElementsMatch(f.T(), []string{"file2", "file3", "file4"}, search("go*", 0))
+	require.ElementsMatch(f.T(), []string{"file1"}, search("j?va", 0))
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, search("[hello TO java]", 0))
+	require.ElementsMatch(f.T(), []string{"file1"}, search("{hello TO java]", 0))
+	require.Empty(f.T(), search("rust*", 0))
+
+	// only golang is left of golang and hello
+	require.ElementsMatch(f.T(), []string{"file2", "file3", "file4"}, search("[* TO hello]", 1))
+}
+
+func (f *searchTestSuite) TestSearchPage() {
+	n, err := Parse("hello world golang", analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	all := Search(f.index, n, f.ranking)
+	require.Len(f.T(), all, 4)
+
+	hits, total := SearchPage(f.index, n, f.ranking, Page{Limit: 2, Offset: 1})
+	require.Equal(f.T(), 4, total)
+	require.Equal(f.T(), all[1:3], hits)
+
+	hits, total = SearchPage(f.index, n, f.ranking, Page{Limit: 2, Offset: 10})
+	require.Equal(f.T(), 4, total)
+	require.Empty(f.T(), hits)
+
+	// pages after the cursors make up all the hits
+	var paged []*Hit
+	var after *Cursor
+	for {
+		hits, _ := SearchPage(f.index, n, f.ranking, Page{Limit: 3, After: after})
+		if len(hits) == 0 {
+			break
+		}
+		paged = append(paged, hits...)
+		cursor, err := ParseCursor(CursorOf(hits[len(hits)-1], Page{}.Sort).String())
+		require.NoError(f.T(), err)
+		after = cursor
+	}
+	require.Equal(f.T(), all, paged)
+}
+
+func (f *searchTestSuite) TestParseInvalidCursor() {
+	for _, c := range []string{"not base64!", "bm90IGpzb24"} {
+		_, err := ParseCursor(c)
+		require.True(f.T(), errors.Is(err, ErrCursor), c)
+	}
+}
+
+func (f *searchTestSuite) TestSort() {
+	modified := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for id, size := range map[int]int64{1: 300, 2: 100, 3: 200, 4: 100} {
+		doc := f.index.Docs[id]
+		doc.Size = size
+		doc.ModTime = modified.AddDate(0, 0, id%3)
+	}
+	n, err := Parse("hello world golang", analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"", []string{"file2", "file1", "file3", "file4"}},
+		{"score:asc", []string{"file3", "file4", "file1", "file2"}},
+		{"filename", []string{"file1", "file2", "file3", "file4"}},
+		{"filename:desc", []string{"file4", "file3", "file2", "file1"}},
+		// file2 and file4 have the same size and file3 and file4 the same score, so they are ordered by score and path
+		{"size", []string{"file2", "file4", "file3", "file1"}},
+		{"size:desc", []string{"file1", "file3", "file2", "file4"}},
+		{"mtime", []string{"file2", "file1", "file4", "file3"}},
+		{"mtime:asc", []string{"file3", "file1", "file4", "file2"}},
+	}
+	for _, tt := range tests {
+		sort, err := ParseSort(tt.sort)
+		require.NoError(f.T(), err, tt.sort)
+
+		hits, _ := SearchPage(f.index, n, f.ranking, Page{Sort: sort})
+		var filenames []string
+		for _, h := range hits {
+			filenames = append(filenames, h.Document.Path)
+		}
+		require.Equal(f.T(), tt.expected, filenames, tt.sort)
+
+		// pages after the cursors make up all the hits in the same order
+		var paged []*Hit
+		var after *Cursor
+		for {
+			page, _ := SearchPage(f.index, n, f.ranking, Page{Limit: 1, After: after, Sort: sort})
+			if len(page) == 0 {
+				break
+			}
+			paged = append(paged, page...)
+			cursor, err := ParseCursor(CursorOf(page[0], sort).String())
+			require.NoError(f.T(), err)
+			require.NoError(f.T(), cursor.Check(sort))
+			after = cursor
+		}
+		require.Equal(f.T(), hits, paged, tt.sort)
+	}
+}
+
+func (f *searchTestSuite) TestParseInvalidSort() {
+	for _, s := range []string{"title", "score:up", ":desc"} {
+		_, err := ParseSort(s)
+		require.True(f.T(), errors.Is(err, ErrSort), s)
+	}
+
+	cursor := CursorOf(&Hit{Document: &index.Document{Path: "file1"}}, Sort{Field: SortSize})
+	require.True(f.T(), errors.Is(cursor.Check(DefaultSort), ErrCursor))
+	require.NoError(f.T(), CursorOf(&Hit{Document: &index.Document{Path: "file1"}}, Sort{}).Check(DefaultSort))
+}
+
+func (f *searchTestSuite) TestFilters() {
+	modified := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for id, relPath := range map[int]string{1: "docs/guide.md", 2: "docs/api/hello.md", 3: "src/main.go", 4: "notes.txt"} {
+		doc := f.index.Docs[id]
+		doc.RelPath, doc.Ext = relPath, index.Extension(relPath)
+		doc.ModTime = modified.AddDate(0, 0, id-1)
+		doc.Size = int64(id) * 1024
+	}
+
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, f.filenames("(hello OR world) path:docs/*"))
+	require.ElementsMatch(f.T(), []string{"file2"}, f.filenames("(hello OR world) path:docs/api"))
+	// filters restrict only their side of OR
+	require.ElementsMatch(f.T(), []string{"file1", "file2", "file3"}, f.filenames("hello OR world path:src"))
+	require.ElementsMatch(f.T(), []string{"file1", "file3"}, f.filenames("java OR ext:go"))
+	require.ElementsMatch(f.T(), []string{"file2", "file3"}, f.filenames("(hello path:docs/api) OR (world ext:go)"))
+	require.ElementsMatch(f.T(), []string{"file3", "file4"}, f.filenames("world -path:docs"))
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, f.filenames("ext:md"))
+	require.ElementsMatch(f.T(), []string{"file3", "file4"}, f.filenames("golang ext:go,txt"))
+	require.ElementsMatch(f.T(), []string{"file3"}, f.filenames("modified:2026-03-17"))
+	require.ElementsMatch(f.T(), []string{"file2", "file3"}, f.filenames("modified:>2026-03-15 modified:<=2026-03-17"))
+	require.ElementsMatch(f.T(), []string{"file1", "file2"}, f.filenames("size:<3kb"))
+	require.ElementsMatch(f.T(), []string{"file4"}, f.filenames("size:>=4096 modified:>2026-03-15T12:00:00Z"))
+
+	// scores don't depend on the filtered out documents
+	n, err := Parse("world", analysis.ForLanguage(analysis.English))
+	require.NoError(f.T(), err)
+	all := Search(f.index, n, f.ranking)
+	filtered := Search(f.index, WithFilters(n, []Filter{mustFilter(f.T(), "path", "src")}), f.ranking)
+	require.Len(f.T(), filtered, 1)
+	for _, h := range all {
+		if h.Document.Path == "file3" {
+			require.Equal(f.T(), h.Score, filtered[0].Score)
+		}
+	}
+}
+
+func (f *searchTestSuite) TestPhraseKeepsStopWordGaps() {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(f.T(), err)
+	defer os.RemoveAll(dir)
+
+	var files []string
+	for i, text := range []string{"inverted index", "inverted the index", "the inverted index of the book"} {
+		filename := filepath.Join(dir, fmt.Sprintf("file%d", i+1))
+		require.NoError(f.T(), ioutil.WriteFile(filename, []byte(text), 0644))
+		files = append(files, filename)
+	}
+	f.index, err = index.CreateInvertedIndex(files, analysis.DefaultSettings())
+	require.NoError(f.T(), err)
+
+	base := func(hits []string) []string {
+		for i := range hits {
+			hits[i] = filepath.Base(hits[i])
+		}
+		return hits
+	}
+	require.ElementsMatch(f.T(), []string{"file1", "file3"}, base(f.filenames(`"inverted index"`)))
+	require.ElementsMatch(f.T(), []string{"file2"}, base(f.filenames(`"inverted the index"`)))
+	// the stop words around the phrase aren't indexed, so they don't restrict it
+	require.ElementsMatch(f.T(), []string{"file1", "file3"}, base(f.filenames(`"the inverted index"`)))
+	require.ElementsMatch(f.T(), []string{"file3"}, base(f.filenames(`"index of the book"`)))
+}
+
+func mustFilter(t *testing.T, field, value string) Filter {
+	filter, err := ParseFilter(field, value)
+	require.NoError(t, err)
+	return filter
+}
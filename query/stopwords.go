@@ -0,0 +1,97 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+// dropper is the analyzer which reports the tokens it drops
+type dropper interface {
+	Dropped(text string) ([]string, error)
+}
+
+// StopWords returns the unique words of the query which are dropped by the analyzer, such as the stop words, in the
+// order of the query. Only the plain words and the phrases are checked, the query has no stop words if the analyzer
+// doesn't report the dropped tokens
+func StopWords(input string, analyzer analysis.Analyzer) ([]string, error) {
+	sw := newStopWords(analyzer)
+	if err := sw.query(input); err != nil {
+		return nil, err
+	}
+	return sw.words, nil
+}
+
+// ClauseStopWords returns the unique words of the structured query which are dropped by the analyzer like StopWords
+// does
+func ClauseStopWords(c *Clause, analyzer analysis.Analyzer) ([]string, error) {
+	sw := newStopWords(analyzer)
+	var err error
+	c.visit(func(text string, query bool) {
+		switch {
+		case err != nil:
+		case query:
+			err = sw.query(text)
+		default:
+			err = sw.add(text)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sw.words, nil
+}
+
+// stopWords collects the unique words dropped by the analyzer
+type stopWords struct {
+	dropper dropper
+	words   []string
+	seen    map[string]struct{}
+}
+
+func newStopWords(analyzer analysis.Analyzer) *stopWords {
+	d, _ := analyzer.(dropper)
+	return &stopWords{dropper: d, words: []string{}, seen: make(map[string]struct{})}
+}
+
+// query adds the stop words of the plain words and the phrases of the query
+func (sw *stopWords) query(input string) error {
+	for _, text := range plainTexts(input) {
+		if err := sw.add(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plainTexts returns the plain words and the phrases of the query, the operators, fuzzy, wildcard and range terms
+// and filters are skipped
+func plainTexts(input string) []string {
+	var texts []string
+	for _, t := range lex(input) {
+		if t.kind != tokenPhrase && (t.kind != tokenWord || strings.ContainsAny(t.value, "*?~") || isFilter(t.value)) {
+			continue
+		}
+		texts = append(texts, t.value)
+	}
+	return texts
+}
+
+// add adds the stop words of the text
+func (sw *stopWords) add(text string) error {
+	if sw.dropper == nil {
+		return nil
+	}
+	dropped, err := sw.dropper.Dropped(text)
+	if err != nil {
+		return fmt.Errorf("error while analyzing words in query: %w", err)
+	}
+	for _, w := range dropped {
+		if _, ok := sw.seen[w]; !ok {
+			sw.seen[w] = struct{}{}
+			sw.words = append(sw.words, w)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+func TestStopWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Words", input: "The golang and THE java", want: []string{"the", "and"}},
+		{name: "Operators aren't words", input: "golang AND NOT java OR (rust)", want: []string{}},
+		{name: "Phrases", input: `"the world of golang" -is`, want: []string{"the", "of", "is"}},
+		{name: "Special terms", input: "th* the~ [a TO the]", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StopWords(tt.input, analysis.ForLanguage(analysis.English))
+			if err != nil {
+				t.Errorf("StopWords() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StopWords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got, err := StopWords("the golang", &analysis.Chain{Tokenizer: strings.Fields})
+	if err != nil || len(got) != 0 {
+		t.Errorf("StopWords() = %v, %v, want no stop words", got, err)
+	}
+}
+
+func TestClauseStopWords(t *testing.T) {
+	c := &Clause{Or: []*Clause{
+		{Term: "the"},
+		{Not: &Clause{Phrase: "world of golang"}},
+		{Query: `"is it" AND java`},
+		{Wildcard: "th*"},
+	}}
+	got, err := ClauseStopWords(c, analysis.ForLanguage(analysis.English))
+	if err != nil {
+		t.Fatalf("ClauseStopWords() error = %v", err)
+	}
+	if want := []string{"the", "of", "is", "it"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ClauseStopWords() = %v, want %v", got, want)
+	}
+}
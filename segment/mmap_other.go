@@ -0,0 +1,20 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package segment
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// mmap reads the whole file into memory on the platforms without mmap support
+func mmap(file *os.File, _ int) ([]byte, func() error, error) {
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error {
+		return nil
+	}, nil
+}
@@ -0,0 +1,290 @@
+package segment
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/rs/zerolog/log"
+)
+
+// termInfo is the entry of the term dictionary
+type termInfo struct {
+	offset  int
+	docFreq int
+	// surface is the surface form of the term, it's empty if it's the term itself
+	surface string
+}
+
+// Segment is the opened read-only segment file. The postings are decoded from the mapped file on demand
+type Segment struct {
+	data   []byte
+	unmap  func() error
+	docs   map[int]*index.Document
+	nextID int
+	// settings are the analysis settings of the index
+	settings analysis.Settings
+	terms    []string
+	dict     map[string]termInfo
+	// dictionary is built on the first fuzzy lookup
+	dictionary     *index.Dictionary
+	dictionaryOnce sync.Once
+}
+
+// Open maps the segment file into memory and verifies it
+func Open(filename string) (*Segment, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < headerSize+crcSize {
+		return nil, ErrFormat
+	}
+
+	data, unmap, err := mmap(file, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("error while mapping segment file: %w", err)
+	}
+
+	s, err := decode(data)
+	if err != nil {
+		_ = unmap()
+		return nil, err
+	}
+	s.unmap = unmap
+
+	log.Debug().
+		Str("filename", filename).
+		Int("documents", len(s.docs)).
+		Int("terms", len(s.terms)).
+		Msg("segment opened")
+
+	return s, nil
+}
+
+// Decode returns the segment of the encoded data
+func Decode(data []byte) (*Segment, error) {
+	if len(data) < headerSize+crcSize {
+		return nil, ErrFormat
+	}
+	return decode(data)
+}
+
+func decode(data []byte) (*Segment, error) {
+	if string(data[:len(magic)]) != magic {
+		return nil, ErrFormat
+	}
+	version := binary.LittleEndian.Uint32(data[8:])
+	if version < 1 || version > Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrFormat, version)
+	}
+
+	body := data[:len(data)-crcSize]
+	if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return nil, ErrCorrupted
+	}
+
+	docsOffset := binary.LittleEndian.Uint64(data[16:])
+	dictOffset := binary.LittleEndian.Uint64(data[24:])
+	if docsOffset < headerSize || docsOffset > dictOffset || dictOffset > uint64(len(body)) {
+		return nil, ErrCorrupted
+	}
+
+	s := &Segment{
+		data: body,
+		docs: make(map[int]*index.Document),
+		dict: make(map[string]termInfo),
+	}
+
+	d := &decoder{data: body[:dictOffset], pos: int(docsOffset)}
+	docCount := d.uvarint()
+	s.nextID = int(d.uvarint())
+	s.settings = analysis.DefaultSettings()
+	if version >= 3 {
+		if err := json.Unmarshal([]byte(d.string()), &s.settings); err != nil && d.err == nil {
+			return nil, ErrCorrupted
+		}
+	}
+	for i := uint64(0); i < docCount && d.err == nil; i++ {
+		doc := &index.Document{}
+		doc.ID = int(d.uvarint())
+		doc.Path = d.string()
+		doc.Title = d.string()
+		doc.Length = int(d.uvarint())
+		doc.Size = d.varint()
+		doc.ModTime = time.Unix(0, d.varint())
+		doc.Hash = d.string()
+		if version >= 2 {
+			doc.Language = analysis.Language(d.string())
+		}
+		if version >= 4 {
+			doc.RelPath = d.string()
+			doc.Ext = d.string()
+		} else {
+			doc.FillMetadata()
+		}
+		s.docs[doc.ID] = doc
+	}
+
+	d = &decoder{data: body, pos: int(dictOffset)}
+	termCount := d.uvarint()
+	for i := uint64(0); i < termCount && d.err == nil; i++ {
+		t := d.string()
+		info := termInfo{
+			offset:  int(d.uvarint()),
+			docFreq: int(d.uvarint()),
+		}
+		if version >= 5 {
+			info.surface = d.string()
+		}
+		if info.offset < headerSize || info.offset >= int(docsOffset) {
+			d.err = ErrCorrupted
+		}
+		s.terms = append(s.terms, t)
+		s.dict[t] = info
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !sort.StringsAreSorted(s.terms) {
+		return nil, ErrCorrupted
+	}
+
+	return s, nil
+}
+
+// Close unmaps the segment file, the segment can't be used after that
+func (s *Segment) Close() error {
+	if s.unmap == nil {
+		return nil
+	}
+	err := s.unmap()
+	s.unmap = nil
+	s.data = nil
+	return err
+}
+
+// Terms returns the sorted terms of the segment
+func (s *Segment) Terms() []string {
+	return s.terms
+}
+
+// Dictionary returns the term dictionary of the segment, it's built on the first call
+func (s *Segment) Dictionary() *index.Dictionary {
+	s.dictionaryOnce.Do(func() {
+		docFreqs := make(map[string]int, len(s.dict))
+		for t, info := range s.dict {
+			docFreqs[t] = info.docFreq
+		}
+		s.dictionary = index.NewFreqDictionary(docFreqs)
+		s.dictionary.SetSurfaces(s.surfaces())
+	})
+	return s.dictionary
+}
+
+// Settings returns the analysis settings of the index
+func (s *Segment) Settings() analysis.Settings {
+	return s.settings
+}
+
+// Documents returns the document table of the segment
+func (s *Segment) Documents() map[int]*index.Document {
+	return s.docs
+}
+
+// Postings returns the postings of the term or nil if the term isn't in the segment
+func (s *Segment) Postings(term string) (index.Postings, error) {
+	info, ok := s.dict[term]
+	if !ok {
+		return nil, nil
+	}
+
+	d := &decoder{data: s.data, pos: info.offset}
+	count := d.uvarint()
+	if count != uint64(info.docFreq) {
+		return nil, ErrCorrupted
+	}
+
+	postings := make(index.Postings, 0, count)
+	var id int
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		id += int(d.uvarint())
+		p := &index.Posting{DocID: id}
+		p.Freq = int(d.uvarint())
+		if uint64(p.Freq) > uint64(len(s.data)) {
+			return nil, ErrCorrupted
+		}
+		p.Positions = make([]int, 0, p.Freq)
+		var pos int
+		for j := 0; j < p.Freq && d.err == nil; j++ {
+			pos += int(d.uvarint())
+			p.Positions = append(p.Positions, pos)
+		}
+		postings = append(postings, p)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return postings, nil
+}
+
+// GetIndex returns the index with the postings of the words and the whole document table. Words which aren't in the
+// segment are skipped
+func (s *Segment) GetIndex(wordArr []string) (*index.Index, error) {
+	ind := index.NewIndex()
+	for _, w := range wordArr {
+		postings, err := s.Postings(w)
+		if err != nil {
+			return nil, err
+		}
+		if postings != nil {
+			ind.Terms[w] = postings
+		}
+	}
+	ind.Docs = s.docs
+	ind.NextID = s.nextID
+	ind.Settings = s.settings
+	return ind, nil
+}
+
+// Load returns the whole index of the segment
+func (s *Segment) Load() (*index.Index, error) {
+	ind, err := s.GetIndex(s.terms)
+	if err != nil {
+		return nil, err
+	}
+
+	// the loaded index may be modified, so it gets its own document table
+	docs := make(map[int]*index.Document, len(s.docs))
+	for id, doc := range s.docs {
+		copied := *doc
+		docs[id] = &copied
+	}
+	ind.Docs = docs
+	ind.Surfaces = s.surfaces()
+	return ind, nil
+}
+
+// surfaces returns the surface forms of the terms which differ from the terms
+func (s *Segment) surfaces() map[string]string {
+	surfaces := make(map[string]string)
+	for t, info := range s.dict {
+		if info.surface != "" {
+			surfaces[t] = info.surface
+		}
+	}
+	return surfaces
+}
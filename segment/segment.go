@@ -0,0 +1,236 @@
+// Package segment implements the self-contained binary file format of the index.
+//
+// The segment file consists of the header, the postings, the document table, the term dictionary and the checksum:
+//
+//	header      magic "SEARCHIX", uint32 version, uint32 flags, uint64 document table offset,
+//	            uint64 term dictionary offset; all the fixed size numbers are little endian
+//	postings    for every term: uvarint number of postings, then for every posting: uvarint delta of the
+//	            document ID, uvarint term frequency and uvarint deltas of the positions
+//	doc table   uvarint number of documents, uvarint next document ID, string JSON encoded analysis settings
+//	            (since version 3), then for every document: uvarint ID,
+//	            string path, string title, uvarint length, varint size, varint modification time in unix
+//	            nanoseconds, string hash, string language (since version 2), string relative path and
+//	            string extension (since version 4)
+//	dictionary  uvarint number of terms, then for every term in the sorted order: string term, uvarint
+//	            offset of its postings, uvarint document frequency, string surface form which is empty if
+//	            it's the term itself (since version 5)
+//	checksum    uint32 CRC-32 (Castagnoli) of all the previous bytes
+//
+// Strings are written as uvarint length followed by the bytes.
+package segment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+const (
+	// Version is the version of the format written by this package
+	Version = 5
+
+	magic      = "SEARCHIX"
+	headerSize = 32
+	crcSize    = 4
+)
+
+var (
+	// ErrFormat is returned when the file isn't a segment or has an unsupported version
+	ErrFormat = errors.New("not a segment file")
+	// ErrCorrupted is returned when the content of the segment doesn't match the checksum or can't be decoded
+	ErrCorrupted = errors.New("segment file is corrupted")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// fileMode is the mode of the new segment file
+const fileMode = 0644
+
+// Write writes the index to the segment file. The file is replaced atomically, so readers never see partial segment.
+// The replaced file keeps its mode
+func Write(filename string, idx *index.Index) error {
+
+	data := Encode(idx)
+
+	mode := os.FileMode(fileMode)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	// the temporary file is created only readable by the owner
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Encode returns the segment of the index
+func Encode(idx *index.Index) []byte {
+
+	e := &encoder{}
+	e.buf.Write(make([]byte, headerSize))
+
+	terms := make([]string, 0, len(idx.Terms))
+	for t := range idx.Terms {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	offsets := make([]int, len(terms))
+	for i, t := range terms {
+		offsets[i] = e.buf.Len()
+		postings := idx.Terms[t]
+		e.uvarint(uint64(len(postings)))
+		var prevID int
+		for _, p := range postings {
+			e.uvarint(uint64(p.DocID - prevID))
+			prevID = p.DocID
+			e.uvarint(uint64(len(p.Positions)))
+			var prevPos int
+			for _, pos := range p.Positions {
+				e.uvarint(uint64(pos - prevPos))
+				prevPos = pos
+			}
+		}
+	}
+
+	// settings consist of strings only, so they are always encoded
+	settings, _ := json.Marshal(idx.Settings)
+
+	docsOffset := e.buf.Len()
+	ids := make([]int, 0, len(idx.Docs))
+	for id := range idx.Docs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	e.uvarint(uint64(len(ids)))
+	e.uvarint(uint64(idx.NextID))
+	e.string(string(settings))
+	for _, id := range ids {
+		doc := idx.Docs[id]
+		e.uvarint(uint64(id))
+		e.string(doc.Path)
+		e.string(doc.Title)
+		e.uvarint(uint64(doc.Length))
+		e.varint(doc.Size)
+		e.varint(doc.ModTime.UnixNano())
+		e.string(doc.Hash)
+		e.string(string(doc.Language))
+		e.string(doc.RelPath)
+		e.string(doc.Ext)
+	}
+
+	dictOffset := e.buf.Len()
+	e.uvarint(uint64(len(terms)))
+	for i, t := range terms {
+		e.string(t)
+		e.uvarint(uint64(offsets[i]))
+		e.uvarint(uint64(len(idx.Terms[t])))
+		e.string(idx.Surfaces[t])
+	}
+
+	data := e.buf.Bytes()
+	copy(data, magic)
+	binary.LittleEndian.PutUint32(data[8:], Version)
+	binary.LittleEndian.PutUint32(data[12:], 0)
+	binary.LittleEndian.PutUint64(data[16:], uint64(docsOffset))
+	binary.LittleEndian.PutUint64(data[24:], uint64(dictOffset))
+
+	sum := make([]byte, crcSize)
+	binary.LittleEndian.PutUint32(sum, crc32.Checksum(data, crcTable))
+	return append(data, sum...)
+}
+
+type encoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.tmp[:], v)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) varint(v int64) {
+	n := binary.PutVarint(e.tmp[:], v)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		d.err = ErrCorrupted
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		d.err = ErrCorrupted
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *decoder) string() string {
+	l := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if l > uint64(len(d.data)-d.pos) {
+		d.err = ErrCorrupted
+		return ""
+	}
+	s := string(d.data[d.pos : d.pos+int(l)])
+	d.pos += int(l)
+	return s
+}
@@ -0,0 +1,139 @@
+package segment
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type segmentTestSuite struct {
+	suite.Suite
+	index *index.Index
+	dir   string
+}
+
+func TestSegmentTestSuite(t *testing.T) {
+	suite.Run(t, new(segmentTestSuite))
+}
+
+func (f *segmentTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(f.T(), err)
+	f.dir = dir
+
+	f.index = index.NewIndex()
+	f.index.NextID = 12
+	f.index.Docs[3] = &index.Document{
+		ID:      3,
+		Path:    "docs/first.txt",
+		RelPath: "first.txt",
+		Ext:     "txt",
+		Title:   "First document",
+		Length:  4,
+		Size:    27,
+		ModTime: time.Unix(0, 1580000000123456789),
+		Hash:    "d2a84f4b8b650937ec8f73cd8be2c74add5a911ba64df27458ed8229da804a26",
+	}
+	f.index.Docs[11] = &index.Document{
+		ID:      11,
+		Path:    "docs/second.txt",
+		Title:   "Second",
+		Length:  300,
+		Size:    2048,
+		ModTime: time.Unix(0, 1590000000000000000),
+	}
+	f.index.Terms["hello"] = index.Postings{
+		{DocID: 3, Freq: 2, Positions: []int{0, 2}},
+		{DocID: 11, Freq: 3, Positions: []int{5, 130, 299}},
+	}
+	f.index.Terms["world"] = index.Postings{
+		{DocID: 3, Freq: 1, Positions: []int{1}},
+	}
+	f.index.Terms["golang"] = index.Postings{
+		{DocID: 11, Freq: 1, Positions: []int{0}},
+	}
+	f.index.Surfaces["world"] = "worlds"
+}
+
+func (f *segmentTestSuite) TearDownTest() {
+	require.NoError(f.T(), os.RemoveAll(f.dir))
+}
+
+func (f *segmentTestSuite) TestRoundTrip() {
+	s, err := Decode(Encode(f.index))
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), []string{"golang", "hello", "world"}, s.Terms())
+
+	actual, err := s.Load()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), f.index, actual)
+	require.Equal(f.T(), "worlds", s.Dictionary().Surface("world"))
+	require.Equal(f.T(), "hello", s.Dictionary().Surface("hello"))
+}
+
+func (f *segmentTestSuite) TestSettings() {
+	f.index.Settings = analysis.Settings{Analyzer: analysis.Stemming, Language: analysis.Russian}
+	s, err := Decode(Encode(f.index))
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), f.index.Settings, s.Settings())
+}
+
+func (f *segmentTestSuite) TestWriteOpen() {
+	filename := filepath.Join(f.dir, "index.seg")
+	require.NoError(f.T(), Write(filename, f.index))
+
+	s, err := Open(filename)
+	require.NoError(f.T(), err)
+	defer s.Close()
+
+	actual, err := s.GetIndex([]string{"hello", "rust"})
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), f.index.Terms["hello"], actual.Terms["hello"])
+	require.NotContains(f.T(), actual.Terms, "rust")
+	require.Equal(f.T(), f.index.Docs, actual.Docs)
+}
+
+func (f *segmentTestSuite) TestWriteMode() {
+	if runtime.GOOS == "windows" {
+		f.T().Skip("file modes aren't supported")
+	}
+	filename := filepath.Join(f.dir, "index.seg")
+	require.NoError(f.T(), Write(filename, f.index))
+	info, err := os.Stat(filename)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), os.FileMode(0644), info.Mode().Perm())
+
+	require.NoError(f.T(), os.Chmod(filename, 0640))
+	require.NoError(f.T(), Write(filename, f.index))
+	info, err = os.Stat(filename)
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), os.FileMode(0640), info.Mode().Perm())
+}
+
+func (f *segmentTestSuite) TestEmptyIndex() {
+	s, err := Decode(Encode(index.NewIndex()))
+	require.NoError(f.T(), err)
+	actual, err := s.Load()
+	require.NoError(f.T(), err)
+	require.Equal(f.T(), index.NewIndex(), actual)
+}
+
+func (f *segmentTestSuite) TestCorrupted() {
+	data := Encode(f.index)
+	data[headerSize+1] ^= 0xff
+	_, err := Decode(data)
+	require.Equal(f.T(), ErrCorrupted, err)
+}
+
+func (f *segmentTestSuite) TestNotSegment() {
+	_, err := Decode([]byte("hello world, this is definitely not a segment file"))
+	require.Equal(f.T(), ErrFormat, err)
+}
@@ -0,0 +1,297 @@
+// Package snippet makes the fragments of the documents around the matched terms with the terms highlighted
+package snippet
+
+import (
+	"html"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+const (
+	// DefaultFragmentSize is the default number of characters in the fragment
+	DefaultFragmentSize = 150
+	// DefaultCount is the default maximum number of the fragments of the document
+	DefaultCount = 2
+	// maxFileSize is the number of bytes from the beginning of the file the fragments are made of
+	maxFileSize = 1 << 20
+
+	// ellipsis marks the text cut off the fragment
+	ellipsis  = "…"
+	openMark  = "<mark>"
+	closeMark = "</mark>"
+)
+
+// Options describe the fragments
+type Options struct {
+	// FragmentSize is the number of characters in the fragment, the fragment is extended to the word boundaries
+	FragmentSize int
+	// Count is the maximum number of the fragments
+	Count int
+}
+
+// span is the matched word in the runes of the text
+type span struct {
+	start, end int
+	term       string
+}
+
+// FromFile reads the beginning of the file and returns its fragments, see Make
+func FromFile(filename string, analyzer analysis.Analyzer, terms map[string]struct{}, opts Options) ([]string,
+	error) {
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(file, maxFileSize))
+	if err != nil {
+		return nil, err
+	}
+	return Make(string(data), analyzer, terms, opts)
+}
+
+// Make returns the HTML fragments of the text with the most matched terms in the order they are in the text. The words
+// analyzed to the terms are wrapped in <mark>, the rest of the text is escaped. The beginning of the text is returned if
+// nothing is matched
+func Make(text string, analyzer analysis.Analyzer, terms map[string]struct{}, opts Options) ([]string, error) {
+	if opts.FragmentSize <= 0 {
+		opts.FragmentSize = DefaultFragmentSize
+	}
+	if opts.Count <= 0 {
+		opts.Count = DefaultCount
+	}
+
+	runes := []rune(text)
+	spans, err := match(runes, analyzer, terms)
+	if err != nil {
+		return nil, err
+	}
+
+	var fragments [][2]int
+	if len(spans) == 0 {
+		fragments = [][2]int{{0, min(len(runes), opts.FragmentSize)}}
+	} else {
+		fragments = choose(spans, len(runes), opts)
+	}
+
+	ans := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		start, end := wordBoundaries(runes, f[0], f[1])
+		if fragment := render(runes, start, end, spans); fragment != "" {
+			ans = append(ans, fragment)
+		}
+	}
+	return ans, nil
+}
+
+// match returns the words of the text analyzed to the terms. Files are analyzed in space separated pieces, so every
+// piece is analyzed by letters first and as a whole if none of its letter runs matches
+func match(runes []rune, analyzer analysis.Analyzer, terms map[string]struct{}) ([]span, error) {
+	// the same words occur in the text many times
+	cache := make(map[string]string)
+	matched := func(word string) (string, error) {
+		if term, ok := cache[word]; ok {
+			return term, nil
+		}
+		analyzed, err := analyzer.Analyze(word)
+		if err != nil {
+			return "", err
+		}
+		var term string
+		for _, a := range analyzed {
+			if _, ok := terms[a]; ok {
+				term = a
+				break
+			}
+		}
+		cache[word] = term
+		return term, nil
+	}
+
+	var spans []span
+	for _, piece := range fields(runes, unicode.IsSpace) {
+		var found bool
+		for _, run := range fields(runes[piece[0]:piece[1]], isWordBreak) {
+			start, end := piece[0]+run[0], piece[0]+run[1]
+			term, err := matched(string(runes[start:end]))
+			if err != nil {
+				return nil, err
+			}
+			if term != "" {
+				spans = append(spans, span{start: start, end: end, term: term})
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		term, err := matched(string(runes[piece[0]:piece[1]]))
+		if err != nil {
+			return nil, err
+		}
+		if term != "" {
+			spans = append(spans, span{start: piece[0], end: piece[1], term: term})
+		}
+	}
+	return spans, nil
+}
+
+// isWordBreak reports whether the rune isn't the part of the word, like in the letter tokenizer
+func isWordBreak(r rune) bool {
+	return !unicode.IsLetter(r) && r != '\''
+}
+
+// fields returns the bounds of the runs of the runes separated by the runes satisfying sep
+func fields(runes []rune, sep func(r rune) bool) [][2]int {
+	var ans [][2]int
+	start := -1
+	for i, r := range runes {
+		if sep(r) {
+			if start >= 0 {
+				ans = append(ans, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		ans = append(ans, [2]int{start, len(runes)})
+	}
+	return ans
+}
+
+// choose returns the bounds of the fragments with the most distinct matched terms and then the most matches. Every
+// fragment begins a bit before its first match, fragments don't overlap
+func choose(spans []span, length int, opts Options) [][2]int {
+	type candidate struct {
+		start, end      int
+		distinct, spans int
+	}
+
+	context := opts.FragmentSize / 4
+	candidates := make([]candidate, 0, len(spans))
+	for i, s := range spans {
+		start := s.start - context
+		if start < 0 {
+			start = 0
+		}
+		end := min(start+opts.FragmentSize, length)
+
+		c := candidate{start: start, end: end}
+		distinct := make(map[string]struct{})
+		for _, other := range spans[i:] {
+			if other.end > end {
+				break
+			}
+			distinct[other.term] = struct{}{}
+			c.spans++
+		}
+		c.distinct = len(distinct)
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distinct != candidates[j].distinct {
+			return candidates[i].distinct > candidates[j].distinct
+		}
+		return candidates[i].spans > candidates[j].spans
+	})
+
+	var chosen [][2]int
+	for _, c := range candidates {
+		if len(chosen) == opts.Count {
+			break
+		}
+		overlaps := false
+		for _, f := range chosen {
+			if c.start < f[1] && f[0] < c.end {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			chosen = append(chosen, [2]int{c.start, c.end})
+		}
+	}
+
+	sort.Slice(chosen, func(i, j int) bool {
+		return chosen[i][0] < chosen[j][0]
+	})
+	return chosen
+}
+
+// wordBoundaries moves the bounds of the fragment so it doesn't cut the words: the start forward to the next word and
+// the end forward to the end of the word
+func wordBoundaries(runes []rune, start, end int) (int, int) {
+	if start > 0 && !unicode.IsSpace(runes[start-1]) {
+		for start < end && !unicode.IsSpace(runes[start]) {
+			start++
+		}
+	}
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	return start, end
+}
+
+// render returns the escaped text of the fragment with the matched words marked. White space is collapsed, so the
+// fragment is a single line
+func render(runes []rune, start, end int, spans []span) string {
+	next := sort.Search(len(spans), func(i int) bool {
+		return spans[i].start >= start
+	})
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	var empty, space = true, false
+	for i := start; i < end; i++ {
+		for next < len(spans) && spans[next].start < i {
+			next++
+		}
+		if unicode.IsSpace(runes[i]) {
+			space = true
+			continue
+		}
+		if space && !empty {
+			b.WriteByte(' ')
+		}
+		space, empty = false, false
+
+		marked := next < len(spans) && spans[next].start == i && spans[next].end <= end
+		if marked {
+			b.WriteString(openMark)
+			b.WriteString(html.EscapeString(string(runes[i:spans[next].end])))
+			b.WriteString(closeMark)
+			i = spans[next].end - 1
+			next++
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+
+	if empty {
+		return ""
+	}
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
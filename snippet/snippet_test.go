@@ -0,0 +1,88 @@
+package snippet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+)
+
+func TestMake(t *testing.T) {
+	stemming := analysis.ForLanguage(analysis.English)
+	whitespace, err := analysis.Settings{Analyzer: analysis.Whitespace}.New(analysis.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("filler words here ", 10)
+
+	tests := []struct {
+		name     string
+		text     string
+		analyzer analysis.Analyzer
+		terms    []string
+		opts     Options
+		want     []string
+	}{
+		{
+			name:     "Stemmed words are highlighted",
+			text:     "Golang is\nfreezing, isn't it?",
+			analyzer: stemming,
+			terms:    []string{"freez", "golang"},
+			want:     []string{"<mark>Golang</mark> is <mark>freezing</mark>, isn&#39;t it?"},
+		},
+		{
+			name:     "Text is escaped",
+			text:     "<b>golang</b> & java",
+			analyzer: stemming,
+			terms:    []string{"java"},
+			want:     []string{"&lt;b&gt;golang&lt;/b&gt; &amp; <mark>java</mark>"},
+		},
+		{
+			name:     "Whole pieces are matched by whitespace analyzer",
+			text:     "hello C++ world",
+			analyzer: whitespace,
+			terms:    []string{"C++"},
+			want:     []string{"hello <mark>C++</mark> world"},
+		},
+		{
+			name:     "Fragments are cut at words",
+			text:     long + "golang " + long + "java " + long,
+			analyzer: stemming,
+			terms:    []string{"golang", "java"},
+			opts:     Options{FragmentSize: 20, Count: 2},
+			want:     []string{"…here <mark>golang</mark> filler words…", "…here <mark>java</mark> filler words…"},
+		},
+		{
+			name:     "Fragment with more distinct terms wins",
+			text:     "golang " + long + "golang java " + long,
+			analyzer: stemming,
+			terms:    []string{"golang", "java"},
+			opts:     Options{FragmentSize: 20, Count: 1},
+			want:     []string{"…here <mark>golang</mark> <mark>java</mark> filler…"},
+		},
+		{
+			name:     "Beginning of the text without matches",
+			text:     long,
+			analyzer: stemming,
+			terms:    []string{"golang"},
+			opts:     Options{FragmentSize: 10},
+			want:     []string{"filler words…"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms := make(map[string]struct{})
+			for _, term := range tt.terms {
+				terms[term] = struct{}{}
+			}
+			got, err := Make(tt.text, tt.analyzer, terms, tt.opts)
+			if err != nil {
+				t.Fatalf("Make() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Make() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,135 @@
 window.onload = function () {
-    document.querySelector('input').addEventListener('keydown', function (e) {
+    const input = document.querySelector('input');
+    const dropdown = document.getElementById("suggestions");
+    const sort = document.getElementById("sort");
+    let suggestions = [];
+    let selected = -1;
+
+    input.addEventListener('keydown', function (e) {
+        if (e.keyCode === 40 || e.keyCode === 38) {
+            // arrows down and up move the selection over the suggestions
+            e.preventDefault();
+            if (suggestions.length > 0) {
+                // -1 is the typed text itself
+                if (e.keyCode === 40) {
+                    selected = selected + 1 < suggestions.length ? selected + 1 : -1;
+                } else {
+                    selected = selected > -1 ? selected - 1 : suggestions.length - 1;
+                }
+                renderSuggestions();
+            }
+            return;
+        }
+        if (e.keyCode === 27) {
+            hideSuggestions();
+            return;
+        }
         if (e.keyCode === 13) {
             e.preventDefault();
-            send(this.value);
-            console.log(this.value);
+            const value = selected >= 0 ? suggestions[selected].text : this.value;
+            hideSuggestions();
+            send(value);
+            console.log(value);
             this.value = "";
         }
     });
+
+    let suggestTimer = null;
+    input.addEventListener('input', function () {
+        // the request is sent when the user pauses typing
+        clearTimeout(suggestTimer);
+        const prefix = this.value;
+        suggestTimer = setTimeout(function () {
+            suggest(prefix);
+        }, 100);
+    });
+    input.addEventListener('blur', function () {
+        // the click on the suggestion happens after the blur
+        setTimeout(hideSuggestions, 200);
+    });
+
+    const suggestRequest = new XMLHttpRequest();
+
+    function suggest(prefix) {
+        suggestRequest.abort();
+        if (prefix.trim() === "") {
+            hideSuggestions();
+            return;
+        }
+        suggestRequest.onreadystatechange = function () {
+            if (suggestRequest.readyState === 4 && suggestRequest.status === 200) {
+                suggestions = JSON.parse(suggestRequest.responseText).suggestions;
+                selected = -1;
+                renderSuggestions();
+            }
+        };
+        suggestRequest.open("GET", "http://localhost:8888/api/suggest?prefix=" + encodeURIComponent(prefix));
+        suggestRequest.send();
+    }
+
+    function renderSuggestions() {
+        if (suggestions.length === 0) {
+            hideSuggestions();
+            return;
+        }
+        let html = "";
+        suggestions.forEach(function (s, i) {
+            html += "<li class='suggestion" + (i === selected ? " selected" : "") + " " + s.kind + "' data-index='" + i +
+                "'>" + escapeHtml(s.text) + "</li>";
+        });
+        dropdown.innerHTML = html;
+        dropdown.style.display = "block";
+    }
+
+    function hideSuggestions() {
+        clearTimeout(suggestTimer);
+        suggestRequest.abort();
+        suggestions = [];
+        selected = -1;
+        dropdown.innerHTML = "";
+        dropdown.style.display = "none";
+    }
+
+    dropdown.addEventListener('mousedown', function (e) {
+        const item = e.target.closest("li");
+        if (item) {
+            e.preventDefault();
+            const value = suggestions[item.dataset.index].text;
+            hideSuggestions();
+            send(value);
+            input.value = "";
+        }
+    });
+
+    sort.addEventListener('change', function () {
+        // the results are ordered again from the first page
+        if (currentQuery !== "") {
+            send(currentQuery);
+        }
+    });
+
+    document.getElementById("hidden-block").addEventListener('click', function (e) {
+        if (e.target.classList.contains("did-you-mean")) {
+            e.preventDefault();
+            send(e.target.textContent);
+        }
+        if (e.target.classList.contains("page-prev")) {
+            send(currentQuery, Math.max(currentOffset - pageSize, 0));
+        }
+        if (e.target.classList.contains("page-next")) {
+            send(currentQuery, currentOffset + pageSize);
+        }
+    });
+
     const request = new XMLHttpRequest();
+    const pageSize = 10;
+    let currentQuery = "";
+    let currentOffset = 0;
+
+    function send(userInput, offset) {
+        currentQuery = userInput;
+        currentOffset = offset || 0;
 
-    function send(userInput) {
         function s() {
 
             if (request.readyState === 4) {
@@ -18,11 +138,14 @@ window.onload = function () {
                     jsonParse(request.responseText);
                     console.log(request.responseText);
                     request.abort();
+                } else if (status !== 0) {
+                    showError(request.responseText);
                 }
             }
         }
 
-        request.open("GET", "http://localhost:8888/api?search=" + userInput);
+        request.open("GET", "http://localhost:8888/api/v1/search?search=" + encodeURIComponent(userInput) +
+            "&limit=" + pageSize + "&offset=" + currentOffset + "&sort=" + encodeURIComponent(sort.value));
         request.onreadystatechange = s;
         request.send();
 
@@ -30,17 +153,74 @@ window.onload = function () {
 
     function jsonParse(json) {
         let res = JSON.parse(json);
-        // document.getElementById("result").innerHTML = createTemplate(res[0].filename, res[0].wordsEncountered);
-        document.getElementById("hidden-block").innerHTML = createTemplate(res[0].filename, res[0].wordsEncountered);
-        console.log(res[0].wordsEncountered);
+        let html = "";
+        if (res.query.missing.length > 0) {
+            html += createMessage("No results for " + res.query.missing.join(", "));
+        }
+        if (res.query.stopWords.length > 0) {
+            html += createMessage("Ignored common words: " + res.query.stopWords.join(", "));
+        }
+        if (res.hits.length === 0 && res.query.missing.length === 0) {
+            html += createMessage("Nothing found");
+        }
+        if (res.query.suggestion) {
+            html += "<span class='message' style='display: block; margin: 5px 20px; color: #888;'>Did you mean " +
+                "<a href='#' class='did-you-mean' style='color: #352A3B;'>" + escapeHtml(res.query.suggestion) + "</a>?</span>";
+        }
+        if (res.total > 0) {
+            html += createMessage("Results " + (currentOffset + 1) + "–" + (currentOffset + res.hits.length) +
+                " of " + res.total + " (" + res.tookMs + " ms)");
+        }
+        res.hits.forEach(function (r) {
+            html += createTemplate(r.filename, r.wordsEncountered, r.snippets || []);
+        });
+        html += createPaging(res.total);
+        document.getElementById("hidden-block").innerHTML = html;
+        console.log(res.hits.length);
+    }
+
+    function showError(json) {
+        let message = "Search failed";
+        try {
+            message = JSON.parse(json).error.message;
+        } catch (e) {
+            console.log(e);
+        }
+        document.getElementById("hidden-block").innerHTML = createMessage(message);
+    }
+
+    function escapeHtml(text) {
+        const span = document.createElement("span");
+        span.textContent = text;
+        return span.innerHTML;
+    }
+
+    function createMessage(text) {
+        return "<span class='message' style='display: block; margin: 5px 20px; color: #888;'>" + escapeHtml(text) + "</span>"
+    }
+
+    function createPaging(total) {
+        if (total <= pageSize) {
+            return "";
+        }
+        return "<span class='paging'>" +
+            "<button class='page-prev'" + (currentOffset === 0 ? " disabled" : "") + ">Previous</button>" +
+            "<button class='page-next'" + (currentOffset + pageSize >= total ? " disabled" : "") + ">Next</button>" +
+            "</span>";
     }
 
-    function createTemplate(filename, words) {
+    function createTemplate(filename, words, snippets) {
+        // snippets are escaped by the server, only the matched words are wrapped in <mark>
+        let snippetsHtml = "";
+        snippets.forEach(function (snippet) {
+            snippetsHtml += "<span class='snippet'>" + snippet + "</span>";
+        });
         return "<span class='result' style='background: white;  margin-bottom: 20px;  display: block;  margin-top: 5px;  width: 450px;  height: auto;  padding: 10px 0;  border: 1px solid #eee;  border-radius: 20px;'> " +
         " <span class='file' style='display: block; margin: 5px 20px;' > " +
-            " <span class='title-file' style='display: inline-block' >" + filename + " </span> " +
+            " <span class='title-file' style='display: inline-block' >" + escapeHtml(filename) + " </span> " +
             "<span class='words-encountered' style='display: inline-block; float: right; padding-left: 20px; border-left: 1px solid #eee;' >" + words + "</span>" +
             "</span> " +
+            snippetsHtml +
         "</span> "
     }
 };
\ No newline at end of file
@@ -0,0 +1,220 @@
+// Package suggest completes the prefixes typed by the user with the indexed terms and the past queries
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+const (
+	// KindTerm is the kind of the completion with the indexed term
+	KindTerm = "term"
+	// KindQuery is the kind of the completion with the past query
+	KindQuery = "query"
+)
+
+// Suggestion is the completion of the prefix
+type Suggestion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+	// Weight is the document frequency of the term or the number of times the query was searched
+	Weight int `json:"weight"`
+}
+
+// cachedLength is the maximum length of the prefixes whose completions are computed in advance. Longer prefixes match
+// few terms, so they are completed by scanning the sorted terms
+const cachedLength = 3
+
+// Terms completes the prefixes with the indexed terms ordered by their document frequency. The short prefixes match
+// most of the terms, so their best completions are kept, longer prefixes are looked up in the sorted terms
+type Terms struct {
+	// terms are sorted by text
+	terms []Suggestion
+	// top are the indices of the best completions of the short prefixes in the order of decreasing weight
+	top   map[string][]int
+	limit int
+}
+
+// NewTerms returns the completions of the dictionary terms, at most limit completions are returned for every prefix.
+// The terms are completed by their surface forms, so the users see the words instead of the stems. The terms with
+// the same surface form are completed once with the highest of their weights
+func NewTerms(dict *index.Dictionary, limit int) *Terms {
+	t := &Terms{
+		terms: make([]Suggestion, 0, dict.Len()),
+		top:   make(map[string][]int),
+		limit: limit,
+	}
+	surfaces := make(map[string]int, dict.Len())
+	for _, term := range dict.Terms() {
+		s := Suggestion{Text: dict.Surface(term), Kind: KindTerm, Weight: dict.DocFreq(term)}
+		if i, ok := surfaces[s.Text]; ok {
+			if s.Weight > t.terms[i].Weight {
+				t.terms[i] = s
+			}
+			continue
+		}
+		surfaces[s.Text] = len(t.terms)
+		t.terms = append(t.terms, s)
+	}
+	sort.Slice(t.terms, func(i, j int) bool {
+		return t.terms[i].Text < t.terms[j].Text
+	})
+
+	for i, s := range t.terms {
+		runes := []rune(s.Text)
+		for l := 0; l <= len(runes) && l <= cachedLength; l++ {
+			prefix := string(runes[:l])
+			t.top[prefix] = t.keep(t.top[prefix], i)
+		}
+	}
+	return t
+}
+
+// keep adds the term to the best completions if it's better than the worst of them
+func (t *Terms) keep(top []int, term int) []int {
+	i := sort.Search(len(top), func(i int) bool {
+		return better(t.terms[term], t.terms[top[i]])
+	})
+	if i >= t.limit {
+		return top
+	}
+	if len(top) < t.limit {
+		top = append(top, 0)
+	}
+	copy(top[i+1:], top[i:])
+	top[i] = term
+	return top
+}
+
+// Complete returns at most limit terms starting with the prefix
+func (t *Terms) Complete(prefix string, limit int) []Suggestion {
+	var ans []Suggestion
+	if top, ok := t.top[prefix]; ok || len([]rune(prefix)) <= cachedLength {
+		for _, i := range top {
+			ans = append(ans, t.terms[i])
+		}
+		return head(ans, limit)
+	}
+
+	from := sort.Search(len(t.terms), func(i int) bool {
+		return t.terms[i].Text >= prefix
+	})
+	for i := from; i < len(t.terms) && strings.HasPrefix(t.terms[i].Text, prefix); i++ {
+		ans = append(ans, t.terms[i])
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		return better(ans[i], ans[j])
+	})
+	return head(ans, limit)
+}
+
+// Queries completes the prefixes with the past queries ordered by the number of times they were searched. It's safe
+// for concurrent use
+type Queries struct {
+	mu sync.RWMutex
+	// queries are sorted, so the queries with the prefix are next to each other
+	queries []string
+	counts  map[string]int
+	max     int
+}
+
+// NewQueries returns the empty log of queries which keeps at most max distinct queries
+func NewQueries(max int) *Queries {
+	return &Queries{
+		counts: make(map[string]int),
+		max:    max,
+	}
+}
+
+// Add records the query. When the log is full the counts of all the queries are halved and the queries searched once
+// are forgotten, so the queries which aren't searched anymore give way to the new ones
+func (q *Queries) Add(query string) {
+	query = Normalize(query)
+	if query == "" {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.counts[query]; !ok {
+		if len(q.queries) >= q.max {
+			q.decay()
+		}
+		i := sort.SearchStrings(q.queries, query)
+		q.queries = append(q.queries, "")
+		copy(q.queries[i+1:], q.queries[i:])
+		q.queries[i] = query
+	}
+	q.counts[query]++
+}
+
+// decay halves the counts and removes the queries with zero count, the caller must hold the lock
+func (q *Queries) decay() {
+	kept := q.queries[:0]
+	for _, query := range q.queries {
+		if q.counts[query] /= 2; q.counts[query] > 0 {
+			kept = append(kept, query)
+		} else {
+			delete(q.counts, query)
+		}
+	}
+	q.queries = kept
+}
+
+// Complete returns at most limit past queries starting with the prefix
+func (q *Queries) Complete(prefix string, limit int) []Suggestion {
+	prefix = Normalize(prefix)
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	var ans []Suggestion
+	for i := sort.SearchStrings(q.queries, prefix); i < len(q.queries); i++ {
+		if !strings.HasPrefix(q.queries[i], prefix) {
+			break
+		}
+		ans = append(ans, Suggestion{Text: q.queries[i], Kind: KindQuery, Weight: q.counts[q.queries[i]]})
+	}
+	sort.SliceStable(ans, func(i, j int) bool {
+		return better(ans[i], ans[j])
+	})
+	return head(ans, limit)
+}
+
+// Normalize returns the lowercase query with the words separated by single spaces
+func Normalize(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// LastWord splits the typed text into the beginning and the lowercase letters of the last word which is completed
+// with the terms. The last word is empty if the text ends with a space
+func LastWord(text string) (rest string, word string) {
+	i := strings.LastIndexFunc(text, unicode.IsSpace)
+	rest, last := text[:i+1], text[i+1:]
+	return rest, strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, last)
+}
+
+// better reports whether the suggestion a goes before b: the heavier first, then in the alphabetical order
+func better(a, b Suggestion) bool {
+	if a.Weight != b.Weight {
+		return a.Weight > b.Weight
+	}
+	return a.Text < b.Text
+}
+
+func head(s []Suggestion, limit int) []Suggestion {
+	if len(s) > limit {
+		s = s[:limit]
+	}
+	return append([]Suggestion{}, s...)
+}
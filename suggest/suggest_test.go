@@ -0,0 +1,124 @@
+package suggest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/index"
+)
+
+func texts(suggestions []Suggestion) []string {
+	ans := []string{}
+	for _, s := range suggestions {
+		ans = append(ans, s.Text)
+	}
+	return ans
+}
+
+func TestTermsComplete(t *testing.T) {
+	terms := NewTerms(index.NewFreqDictionary(map[string]int{
+		"go": 5, "golang": 10, "gopher": 2, "google": 10, "goroutine": 7, "goroutines": 1, "java": 3,
+	}), 3)
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{prefix: "go", limit: 3, want: []string{"golang", "google", "goroutine"}},
+		{prefix: "go", limit: 10, want: []string{"golang", "google", "goroutine"}},
+		{prefix: "gor", limit: 1, want: []string{"goroutine"}},
+		{prefix: "gorout", limit: 3, want: []string{"goroutine", "goroutines"}},
+		{prefix: "", limit: 2, want: []string{"golang", "google"}},
+		{prefix: "rust", limit: 3, want: []string{}},
+		{prefix: "ru", limit: 3, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := texts(terms.Complete(tt.prefix, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Complete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTermsCompleteSurfaces(t *testing.T) {
+	dict := index.NewFreqDictionary(map[string]int{"compar": 4, "compil": 2, "compiler": 3, "compute": 1})
+	dict.SetSurfaces(map[string]string{"compar": "compare", "compil": "compiler"})
+	terms := NewTerms(dict, 3)
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "comp", want: []string{"compare", "compiler", "compute"}},
+		{prefix: "compa", want: []string{"compare"}},
+		{prefix: "compar", want: []string{"compare"}},
+		{prefix: "compil", want: []string{"compiler"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := texts(terms.Complete(tt.prefix, 3)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Complete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := terms.Complete("compiler", 1); got[0].Weight != 3 {
+		t.Errorf("Complete() weight = %d, want 3", got[0].Weight)
+	}
+}
+
+func TestQueriesComplete(t *testing.T) {
+	q := NewQueries(10)
+	q.Add("golang  Channels")
+	q.Add("golang generics")
+	q.Add("golang channels")
+	q.Add("java")
+	q.Add("  ")
+
+	if got, want := q.Complete("Golang", 10), []Suggestion{
+		{Text: "golang channels", Kind: KindQuery, Weight: 2},
+		{Text: "golang generics", Kind: KindQuery, Weight: 1},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Complete() = %v, want %v", got, want)
+	}
+	if got := texts(q.Complete("rust", 10)); len(got) != 0 {
+		t.Errorf("Complete() = %v, want nothing", got)
+	}
+}
+
+func TestQueriesDecay(t *testing.T) {
+	q := NewQueries(3)
+	for i := 0; i < 4; i++ {
+		q.Add("popular")
+	}
+	for i := 0; i < 5; i++ {
+		q.Add(fmt.Sprintf("query %d", i))
+	}
+
+	if got := texts(q.Complete("popular", 10)); !reflect.DeepEqual(got, []string{"popular"}) {
+		t.Errorf("Complete() = %v, the popular query must be kept", got)
+	}
+	if got := texts(q.Complete("query", 10)); !reflect.DeepEqual(got, []string{"query 4"}) {
+		t.Errorf("Complete() = %v, old queries must be forgotten", got)
+	}
+}
+
+func TestLastWord(t *testing.T) {
+	tests := []struct {
+		text, rest, word string
+	}{
+		{text: "Gola", rest: "", word: "gola"},
+		{text: "hello Wor", rest: "hello ", word: "wor"},
+		{text: "hello +(wor", rest: "hello ", word: "wor"},
+		{text: "hello ", rest: "hello ", word: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if rest, word := LastWord(tt.text); rest != tt.rest || word != tt.word {
+				t.Errorf("LastWord() = %q, %q, want %q, %q", rest, word, tt.rest, tt.word)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Package synonym implements the synonym dictionary in the Solr format applied to the queries.
+//
+// Every line of the dictionary is a rule, the text after '#' is a comment:
+//
+//	car, automobile, auto    - equivalent phrases, every one of them is expanded with the others
+//	tv, television => telly  - explicit mapping, the phrases on the left are replaced by the ones on the right
+//
+// Phrases are matched case-insensitively by their words, so "New York" matches the query phrase "new-york".
+package synonym
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrSyntax is returned when the rule of the dictionary can't be parsed
+var ErrSyntax = errors.New("synonym rule syntax error")
+
+// rule is the expansion of the phrase
+type rule struct {
+	synonyms []string
+	// replace reports whether the phrase is replaced by the synonyms instead of being expanded with them
+	replace bool
+}
+
+// Dictionary maps the normalized phrases to their synonyms
+type Dictionary struct {
+	rules map[string]*rule
+}
+
+// Parse reads the rules of the dictionary
+func Parse(r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{rules: make(map[string]*rule)}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if err := d.parseRule(text); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Load reads the dictionary from the file
+func Load(filename string) (*Dictionary, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+func (d *Dictionary) parseRule(text string) error {
+	sides := strings.Split(text, "=>")
+	switch len(sides) {
+	case 1:
+		phrases, err := phrases(sides[0])
+		if err != nil {
+			return err
+		}
+		for _, p := range phrases {
+			d.add(p, phrases, false)
+		}
+	case 2:
+		from, err := phrases(sides[0])
+		if err != nil {
+			return err
+		}
+		to, err := phrases(sides[1])
+		if err != nil {
+			return err
+		}
+		for _, p := range from {
+			d.add(p, to, true)
+		}
+	default:
+		return fmt.Errorf("%w: several '=>' in %q", ErrSyntax, text)
+	}
+	return nil
+}
+
+// add merges the synonyms into the rule of the phrase, the phrase itself is never its synonym
+func (d *Dictionary) add(phrase string, synonyms []string, replace bool) {
+	r, ok := d.rules[phrase]
+	if !ok {
+		r = &rule{}
+		d.rules[phrase] = r
+	}
+	r.replace = r.replace || replace
+
+	for _, s := range synonyms {
+		if s == phrase && !replace {
+			continue
+		}
+		if !contains(r.synonyms, s) {
+			r.synonyms = append(r.synonyms, s)
+		}
+	}
+}
+
+// Lookup returns the synonyms of the raw query phrase and reports whether the phrase must be replaced by them
+func (d *Dictionary) Lookup(raw string) (synonyms []string, replace bool) {
+	if d == nil {
+		return nil, false
+	}
+	r, ok := d.rules[Normalize(raw)]
+	if !ok {
+		return nil, false
+	}
+	return r.synonyms, r.replace
+}
+
+// Len returns the number of the phrases which have synonyms
+func (d *Dictionary) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.rules)
+}
+
+// Normalize returns the lowercase words of the phrase separated by single spaces
+func Normalize(phrase string) string {
+	return strings.Join(analysis.LetterTokenizer(strings.ToLower(phrase)), " ")
+}
+
+// phrases returns the normalized comma separated phrases
+func phrases(text string) ([]string, error) {
+	var ans []string
+	for _, p := range strings.Split(text, ",") {
+		if p = Normalize(p); p == "" {
+			return nil, fmt.Errorf("%w: empty phrase in %q", ErrSyntax, text)
+		}
+		ans = append(ans, p)
+	}
+	sort.Strings(ans)
+	return ans, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// File is the dictionary loaded from the file which can be reloaded without restarting the server. It's safe for
+// concurrent use
+type File struct {
+	filename string
+	mu       sync.RWMutex
+	dict     *Dictionary
+}
+
+// NewFile loads the dictionary from the file
+func NewFile(filename string) (*File, error) {
+	f := &File{filename: filename}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Dictionary returns the last loaded dictionary or nil if there is no file
+func (f *File) Dictionary() *Dictionary {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.dict
+}
+
+// Reload reads the file again, the previous dictionary is kept if the file can't be read
+func (f *File) Reload() error {
+	dict, err := Load(f.filename)
+	if err != nil {
+		log.Err(err).Str("filename", f.filename).Msg("error while loading synonyms")
+		return err
+	}
+
+	f.mu.Lock()
+	f.dict = dict
+	f.mu.Unlock()
+
+	log.Info().Str("filename", f.filename).Int("phrases", dict.Len()).Msg("synonyms loaded")
+	return nil
+}
@@ -0,0 +1,117 @@
+package synonym
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dictionary = `# equivalent phrases
+car, Automobile, auto
+tv, television => telly   # explicit mapping
+New York, NYC
+car, vehicle
+`
+
+func TestLookup(t *testing.T) {
+	d, err := Parse(strings.NewReader(dictionary))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		raw         string
+		want        []string
+		wantReplace bool
+	}{
+		{
+			name: "Equivalent phrases",
+			raw:  "automobile",
+			want: []string{"auto", "car"},
+		},
+		{
+			name: "Rules are merged",
+			raw:  "Car",
+			want: []string{"auto", "automobile", "vehicle"},
+		},
+		{
+			name:        "Explicit mapping",
+			raw:         "TV",
+			want:        []string{"telly"},
+			wantReplace: true,
+		},
+		{
+			name: "Mapping is one-way",
+			raw:  "telly",
+		},
+		{
+			name: "Phrase",
+			raw:  "new-york",
+			want: []string{"nyc"},
+		},
+		{
+			name: "Unknown phrase",
+			raw:  "bicycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, replace := d.Lookup(tt.raw)
+			if !reflect.DeepEqual(got, tt.want) || replace != tt.wantReplace {
+				t.Errorf("Lookup() got = %v, %v, want %v, %v", got, replace, tt.want, tt.wantReplace)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"car, , auto", "a => b => c", "=> telly"} {
+		if _, err := Parse(strings.NewReader("tv, telly\n" + input)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q) error = %v, want ErrSyntax", input, err)
+		} else if !strings.HasPrefix(err.Error(), "line 2:") {
+			t.Errorf("Parse(%q) error = %v, want line number", input, err)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "synonym")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "synonyms.txt")
+	if err := ioutil.WriteFile(filename, []byte("car, auto"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(filename)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("car, vehicle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, _ := f.Dictionary().Lookup("car"); !reflect.DeepEqual(got, []string{"vehicle"}) {
+		t.Errorf("Lookup() got = %v after reload", got)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("car, , vehicle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reload(); err == nil {
+		t.Errorf("Reload() of the broken file succeeded")
+	}
+	if got, _ := f.Dictionary().Lookup("car"); !reflect.DeepEqual(got, []string{"vehicle"}) {
+		t.Errorf("Lookup() got = %v, the previous dictionary must be kept", got)
+	}
+}
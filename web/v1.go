@@ -0,0 +1,381 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/query"
+	"github.com/rs/zerolog/log"
+)
+
+// apiVersion is the version of the response envelope
+const apiVersion = "v1"
+
+// Codes of the API errors
+const (
+	codeInvalidQuery     = "invalid_query"
+	codeInvalidParameter = "invalid_parameter"
+	codeInvalidBody      = "invalid_body"
+	codeUnknownLanguage  = "unknown_language"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeInternal         = "internal_error"
+)
+
+// apiError is the error of the API request with the HTTP status and the stable code clients may rely on
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// newAPIError returns the API error of the status with the code chosen by the cause. The messages of the internal
+// errors aren't shown to the client
+func newAPIError(status int, err error) *apiError {
+	e := &apiError{Status: status, Code: codeInvalidParameter, Message: err.Error()}
+	switch {
+	case status >= http.StatusInternalServerError:
+		e.Code, e.Message = codeInternal, http.StatusText(status)
+	case errors.Is(err, query.ErrSyntax):
+		e.Code = codeInvalidQuery
+	case errors.Is(err, analysis.ErrUnknownLanguage):
+		e.Code = codeUnknownLanguage
+	}
+	return e
+}
+
+// errorEnvelope is the body of the failed API response
+type errorEnvelope struct {
+	Version string    `json:"version"`
+	Error   *apiError `json:"error"`
+}
+
+// queryInfo describes how the search phrase is understood
+type queryInfo struct {
+	// Raw is the query string or the words of the structured query clause
+	Raw string `json:"raw"`
+	// Terms are the normalized terms of the query including the synonyms and the fuzzy, wildcard and range expansions
+	Terms []string `json:"terms"`
+	// StopWords are the words of the query which are ignored by the analyzer
+	StopWords []string `json:"stopWords"`
+	// Missing are the words of the query which aren't in the index
+	Missing []string `json:"missing"`
+	// Suggestion is the spelling-corrected query offered when nothing is found by the query string
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// searchEnvelope is the body of the successful search response of the versioned API
+type searchEnvelope struct {
+	Version string `json:"version"`
+	// Hits are the results or only their requested fields
+	Hits  interface{} `json:"hits"`
+	Total int         `json:"total"`
+	// Next is the cursor of the next page which is passed in the after parameter, it's empty on the last page
+	Next string `json:"next,omitempty"`
+	// Sort is the order of the hits written as field:asc or field:desc
+	Sort   string    `json:"sort"`
+	TookMs int64     `json:"tookMs"`
+	Query  queryInfo `json:"query"`
+}
+
+// maxBodySize is the maximum size of the JSON body of the request
+const maxBodySize = 1 << 20
+
+// resultFields are the names of the fields of the search result which may be requested
+var resultFields = map[string]bool{
+	"id": true, "filename": true, "relPath": true, "ext": true, "title": true, "size": true, "modTime": true,
+	"wordsEncountered": true, "score": true, "snippets": true,
+}
+
+// searchBody is the JSON body of the POST search, the omitted options have the same defaults as the query parameters
+// of the GET search
+type searchBody struct {
+	// Query is the query string in the query syntax or the structured query clause
+	Query  json.RawMessage `json:"query"`
+	Lang   string          `json:"lang"`
+	Fuzzy  *bool           `json:"fuzzy"`
+	Limit  *int            `json:"limit"`
+	Offset int             `json:"offset"`
+	After  string          `json:"after"`
+	// Sort is the order of the hits written like the sort parameter, e.g. "mtime:desc"
+	Sort string `json:"sort"`
+	// Highlight are the options of the snippets
+	Highlight *highlightBody `json:"highlight"`
+	// Fields are the fields of the results to return, all the fields are returned if it's empty
+	Fields []string `json:"fields"`
+	// Filters are the metadata filters where key is the field and value is written like in the query, e.g.
+	// {"ext": "md", "modified": ">2026-01-01"}
+	Filters map[string]string `json:"filters"`
+}
+
+// highlightBody are the snippet options of the POST search, zero values mean the server defaults
+type highlightBody struct {
+	FragmentSize int `json:"fragmentSize"`
+	Count        int `json:"count"`
+}
+
+// searchV1Handler answers the search by the query parameters with the versioned envelope. The fields parameter is the
+// comma separated list of the fields of the results to return
+func (s *service) searchV1Handler(writer http.ResponseWriter, request *http.Request) {
+	start := time.Now()
+
+	opts, apiErr := s.formOptions(request)
+	if apiErr != nil {
+		renderError(writer, request, apiErr)
+		return
+	}
+
+	var fields []string
+	if f := request.FormValue("fields"); f != "" {
+		fields = strings.Split(f, ",")
+	}
+	if apiErr := selectFields(opts, fields); apiErr != nil {
+		renderError(writer, request, apiErr)
+		return
+	}
+
+	if apiErr := s.renderSearch(writer, request, opts, fields, start); apiErr != nil {
+		renderError(writer, request, apiErr)
+	}
+}
+
+// searchBodyHandler answers the search described by the JSON body with the versioned envelope. It's evaluated the same
+// way as the search by the query parameters. The body which doesn't match the schema is answered with 400 and the body
+// with the invalid values, e.g. the query syntax or the filters, is answered with 422
+func (s *service) searchBodyHandler(writer http.ResponseWriter, request *http.Request) {
+	start := time.Now()
+
+	body, apiErr := decodeSearchBody(writer, request)
+	if apiErr != nil {
+		renderError(writer, request, apiErr)
+		return
+	}
+
+	opts, apiErr := s.bodyOptions(body)
+	if apiErr != nil {
+		renderError(writer, request, unprocessable(apiErr))
+		return
+	}
+	if apiErr := selectFields(opts, body.Fields); apiErr != nil {
+		renderError(writer, request, unprocessable(apiErr))
+		return
+	}
+
+	if apiErr := s.renderSearch(writer, request, opts, body.Fields, start); apiErr != nil {
+		renderError(writer, request, unprocessable(apiErr))
+	}
+}
+
+// decodeSearchBody reads the JSON body of the search, the unknown fields are rejected so typos don't go unnoticed
+func decodeSearchBody(writer http.ResponseWriter, request *http.Request) (*searchBody, *apiError) {
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	body := &searchBody{}
+	if err := dec.Decode(body); err != nil {
+		log.Err(err).Msg("error while decoding search body")
+		return nil, invalidBody("invalid JSON body: " + err.Error())
+	}
+	if dec.More() {
+		return nil, invalidBody("invalid JSON body: unexpected data after the search object")
+	}
+	return body, nil
+}
+
+// bodyOptions returns the search options of the JSON body
+func (s *service) bodyOptions(body *searchBody) (*searchOptions, *apiError) {
+	opts := &searchOptions{
+		lang:         body.Lang,
+		typoTolerant: s.typoTolerant,
+	}
+	if body.Fuzzy != nil {
+		opts.typoTolerant = *body.Fuzzy
+	}
+
+	raw := bytes.TrimSpace(body.Query)
+	switch {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+		return nil, invalidBody("query is required")
+	case raw[0] == '"':
+		if err := json.Unmarshal(raw, &opts.phrase); err != nil {
+			return nil, invalidBody("invalid query: " + err.Error())
+		}
+	case raw[0] == '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&opts.clause); err != nil {
+			return nil, invalidBody("invalid query: " + err.Error())
+		}
+	default:
+		return nil, invalidBody("query must be a string or an object")
+	}
+
+	fields := make([]string, 0, len(body.Filters))
+	for field := range body.Filters {
+		fields = append(fields, field)
+	}
+	// filters are applied in the same order for the same body
+	sort.Strings(fields)
+	for _, field := range fields {
+		f, err := query.ParseFilter(field, body.Filters[field])
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, fmt.Errorf("invalid filters: %w", err))
+		}
+		opts.filters = append(opts.filters, f)
+	}
+
+	limit := s.pageSize
+	if body.Limit != nil {
+		limit = *body.Limit
+	}
+	page, err := newPage(limit, body.Offset, body.After, body.Sort)
+	if err != nil {
+		return nil, newAPIError(http.StatusBadRequest, err)
+	}
+	opts.page = page
+
+	snippets := s.snippets
+	if h := body.Highlight; h != nil {
+		if h.FragmentSize < 0 || h.Count < 0 {
+			return nil, newAPIError(http.StatusBadRequest, errors.New("highlight options must not be negative"))
+		}
+		if h.FragmentSize > 0 {
+			snippets.FragmentSize = h.FragmentSize
+		}
+		if h.Count > 0 {
+			snippets.Count = h.Count
+		}
+	}
+	opts.snippets = &snippets
+
+	return opts, nil
+}
+
+// selectFields validates the requested fields of the results, the snippets aren't made if they aren't requested
+func selectFields(opts *searchOptions, fields []string) *apiError {
+	if len(fields) == 0 {
+		return nil
+	}
+	snippets := false
+	for _, f := range fields {
+		if !resultFields[f] {
+			return newAPIError(http.StatusBadRequest, fmt.Errorf("unknown field %q", f))
+		}
+		snippets = snippets || f == "snippets"
+	}
+	if !snippets {
+		opts.snippets = nil
+	}
+	return nil
+}
+
+// renderSearch searches with the options and writes the versioned envelope with the requested fields of the results.
+// The error of the search isn't written, it's returned to the caller
+func (s *service) renderSearch(writer http.ResponseWriter, request *http.Request, opts *searchOptions, fields []string,
+	start time.Time) *apiError {
+
+	answer, apiErr := s.search(opts)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	missing := answer.result.Missing
+	if missing == nil {
+		missing = []string{}
+	}
+	render.JSON(writer, request, &searchEnvelope{
+		Version: apiVersion,
+		Hits:    project(answer.result.Results, fields),
+		Total:   answer.result.Total,
+		Next:    answer.result.Next,
+		Sort:    opts.page.Sort.String(),
+		TookMs:  time.Since(start).Milliseconds(),
+		Query: queryInfo{
+			Raw:        opts.text(),
+			Terms:      answer.terms,
+			StopWords:  answer.stopWords,
+			Missing:    missing,
+			Suggestion: answer.result.Suggestion,
+		},
+	})
+	return nil
+}
+
+// project returns the results with only the requested fields or the whole results if no fields are requested
+func project(results []*searchResponse, fields []string) interface{} {
+	if len(fields) == 0 {
+		return results
+	}
+	ans := make([]map[string]interface{}, 0, len(results))
+	for _, r := range results {
+		all := map[string]interface{}{
+			"id":               r.ID,
+			"filename":         r.Filename,
+			"relPath":          r.RelPath,
+			"ext":              r.Ext,
+			"title":            r.Title,
+			"size":             r.Size,
+			"modTime":          r.ModTime,
+			"wordsEncountered": r.WordsEncountered,
+			"score":            r.Score,
+			"snippets":         r.Snippets,
+		}
+		hit := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			hit[f] = all[f]
+		}
+		ans = append(ans, hit)
+	}
+	return ans
+}
+
+func invalidBody(message string) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Code: codeInvalidBody, Message: message}
+}
+
+// unprocessable returns the error of the invalid values of the well-formed body, the body which doesn't match the
+// schema and the internal errors keep their status
+func unprocessable(apiErr *apiError) *apiError {
+	if apiErr.Status != http.StatusBadRequest || apiErr.Code == codeInvalidBody {
+		return apiErr
+	}
+	e := *apiErr
+	e.Status = http.StatusUnprocessableEntity
+	return &e
+}
+
+// renderError writes the API error as the JSON body with its status
+func renderError(writer http.ResponseWriter, request *http.Request, apiErr *apiError) {
+	log.Debug().Int("status", apiErr.Status).Str("code", apiErr.Code).Msg(apiErr.Message)
+
+	render.Status(request, apiErr.Status)
+	render.JSON(writer, request, &errorEnvelope{Version: apiVersion, Error: apiErr})
+}
+
+func notFoundHandler(writer http.ResponseWriter, request *http.Request) {
+	renderError(writer, request, &apiError{
+		Status:  http.StatusNotFound,
+		Code:    codeNotFound,
+		Message: "no such endpoint: " + request.URL.Path,
+	})
+}
+
+func methodNotAllowedHandler(writer http.ResponseWriter, request *http.Request) {
+	renderError(writer, request, &apiError{
+		Status:  http.StatusMethodNotAllowed,
+		Code:    codeMethodNotAllowed,
+		Message: "method " + request.Method + " isn't allowed for " + request.URL.Path,
+	})
+}
@@ -0,0 +1,292 @@
+package web
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
+	"github.com/polisgo2020/search-Arkronzxc/config"
+	"github.com/polisgo2020/search-Arkronzxc/db"
+	"github.com/polisgo2020/search-Arkronzxc/index"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type v1TestSuite struct {
+	suite.Suite
+	dir     string
+	service *service
+	router  http.Handler
+}
+
+// testEnvelope is the search envelope with the hits decoded as the JSON objects
+type testEnvelope struct {
+	Version string                   `json:"version"`
+	Hits    []map[string]interface{} `json:"hits"`
+	Total   int                      `json:"total"`
+	Sort    string                   `json:"sort"`
+	Query   queryInfo                `json:"query"`
+}
+
+func TestV1TestSuite(t *testing.T) {
+	suite.Run(t, new(v1TestSuite))
+}
+
+func (f *v1TestSuite) SetupTest() {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(f.T(), err)
+	f.dir = dir
+
+	var files []string
+	for name, content := range map[string]string{
+		"file1.txt": "hello world of the search",
+		"file2.md":  "golang world",
+		"file3.txt": "java",
+	} {
+		filename := filepath.Join(dir, name)
+		require.NoError(f.T(), ioutil.WriteFile(filename, []byte(content), 0644))
+		files = append(files, filename)
+	}
+
+	settings := analysis.Settings{Analyzer: analysis.Stemming, Language: analysis.English}
+	idx, err := index.CreateInvertedIndex(files, settings)
+	require.NoError(f.T(), err)
+	idx.SetRoot(dir)
+	store := db.NewMemoryStore()
+	require.NoError(f.T(), store.SaveIndex(*idx))
+
+	f.service = newService(store, nil, &config.Config{
+		MaxExpansions: 50,
+		SuggestLimit:  10,
+		QueryLogSize:  100,
+		SnippetSize:   150,
+		SnippetCount:  2,
+		PageSize:      10,
+		BM25K1:        1.2,
+		BM25B:         0.75,
+	})
+	f.router = newRouter(f.service)
+}
+
+func (f *v1TestSuite) TearDownTest() {
+	require.NoError(f.T(), os.RemoveAll(f.dir))
+}
+
+// do sends the request to the router and returns the response
+func (f *v1TestSuite) do(method, target, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	f.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// search returns the envelope of the successful search
+func (f *v1TestSuite) search(method, target, body string) *testEnvelope {
+	recorder := f.do(method, target, body)
+	require.Equal(f.T(), http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Contains(f.T(), recorder.Header().Get("Content-Type"), "application/json")
+
+	envelope := &testEnvelope{}
+	require.NoError(f.T(), json.Unmarshal(recorder.Body.Bytes(), envelope))
+	require.Equal(f.T(), apiVersion, envelope.Version)
+	return envelope
+}
+
+func (f *v1TestSuite) filenames(envelope *testEnvelope) []string {
+	var ans []string
+	for _, hit := range envelope.Hits {
+		ans = append(ans, hit["relPath"].(string))
+	}
+	return ans
+}
+
+func (f *v1TestSuite) TestGetEnvelope() {
+	envelope := f.search(http.MethodGet, "/api/v1/search?search="+url.QueryEscape("the world rust"), "")
+
+	require.Equal(f.T(), 2, envelope.Total)
+	require.ElementsMatch(f.T(), []string{"file1.txt", "file2.md"}, f.filenames(envelope))
+	require.Equal(f.T(), "score:desc", envelope.Sort)
+	require.Equal(f.T(), queryInfo{
+		Raw:       "the world rust",
+		Terms:     []string{"rust", "world"},
+		StopWords: []string{"the"},
+		Missing:   []string{"rust"},
+	}, envelope.Query)
+	require.Len(f.T(), f.service.queries.Complete("the", 10), 1)
+
+	// the missing words are reported as they are typed instead of their stems
+	envelope = f.search(http.MethodGet, "/api/v1/search?search="+url.QueryEscape("Xyzzy searching"), "")
+	require.Equal(f.T(), []string{"xyzzy"}, envelope.Query.Missing)
+	require.Equal(f.T(), []string{"search", "xyzzi"}, envelope.Query.Terms)
+}
+
+func (f *v1TestSuite) TestGetFields() {
+	envelope := f.search(http.MethodGet, "/api/v1/search?search=golang&fields=relPath,score", "")
+
+	require.Len(f.T(), envelope.Hits, 1)
+	require.Equal(f.T(), "file2.md", envelope.Hits[0]["relPath"])
+	require.Len(f.T(), envelope.Hits[0], 2)
+}
+
+func (f *v1TestSuite) TestPostQueryString() {
+	envelope := f.search(http.MethodPost, "/api/v1/search",
+		`{"query": "world", "filters": {"ext": "md"}, "limit": 1, "fields": ["relPath"]}`)
+
+	require.Equal(f.T(), 1, envelope.Total)
+	require.Equal(f.T(), []string{"file2.md"}, f.filenames(envelope))
+	require.Equal(f.T(), "world", envelope.Query.Raw)
+}
+
+func (f *v1TestSuite) TestPostClause() {
+	envelope := f.search(http.MethodPost, "/api/v1/search",
+		`{"query": {"or": [{"term": "hello"}, {"phrase": "golang world"}]}}`)
+
+	require.Equal(f.T(), 2, envelope.Total)
+	require.ElementsMatch(f.T(), []string{"file1.txt", "file2.md"}, f.filenames(envelope))
+	require.Equal(f.T(), "hello golang world", envelope.Query.Raw)
+	// the words of the clause aren't the query string, so they aren't completed
+	require.Empty(f.T(), f.service.queries.Complete("hello", 10))
+
+	envelope = f.search(http.MethodPost, "/api/v1/search", `{"query": {"term": "helo"}}`)
+	require.Zero(f.T(), envelope.Total)
+	require.Empty(f.T(), envelope.Query.Suggestion)
+}
+
+func (f *v1TestSuite) TestEmptyQuery() {
+	for _, target := range []string{"/api/v1/search?search=", "/api/v1/search?search=" + url.QueryEscape("  ")} {
+		envelope := f.search(http.MethodGet, target, "")
+		require.Zero(f.T(), envelope.Total)
+		require.Empty(f.T(), envelope.Hits)
+	}
+	envelope := f.search(http.MethodPost, "/api/v1/search", `{"query": ""}`)
+	require.Zero(f.T(), envelope.Total)
+
+	recorder := f.do(http.MethodGet, "/api?search=", "")
+	require.Equal(f.T(), http.StatusOK, recorder.Code, recorder.Body.String())
+	result := &searchResult{}
+	require.NoError(f.T(), json.Unmarshal(recorder.Body.Bytes(), result))
+	require.Zero(f.T(), result.Total)
+	require.Empty(f.T(), result.Results)
+}
+
+func (f *v1TestSuite) TestSuggestSurfaces() {
+	filename := filepath.Join(f.dir, "file4.txt")
+	require.NoError(f.T(), ioutil.WriteFile(filename, []byte("comparing the compared"), 0644))
+	_, _, err := db.AddDocument(f.service.repo, filename, f.dir)
+	require.NoError(f.T(), err)
+
+	recorder := f.do(http.MethodGet, "/api/suggest?prefix="+url.QueryEscape("golang comp"), "")
+	require.Equal(f.T(), http.StatusOK, recorder.Code, recorder.Body.String())
+	result := &suggestResult{}
+	require.NoError(f.T(), json.Unmarshal(recorder.Body.Bytes(), result))
+	require.Len(f.T(), result.Suggestions, 1)
+	require.Equal(f.T(), "golang compared", result.Suggestions[0].Text)
+}
+
+func (f *v1TestSuite) TestErrors() {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "Query syntax", method: http.MethodGet, target: "/api/v1/search?search=%28hello",
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidQuery,
+		},
+		{
+			name: "Invalid limit", method: http.MethodGet, target: "/api/v1/search?search=hello&limit=0",
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidParameter,
+		},
+		{
+			name: "Unknown field", method: http.MethodGet, target: "/api/v1/search?search=hello&fields=owner",
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidParameter,
+		},
+		{
+			name: "Unknown endpoint", method: http.MethodGet, target: "/api/v1/find",
+			wantStatus: http.StatusNotFound, wantCode: codeNotFound,
+		},
+		{
+			name: "Method not allowed", method: http.MethodDelete, target: "/api/v1/search",
+			wantStatus: http.StatusMethodNotAllowed, wantCode: codeMethodNotAllowed,
+		},
+		{
+			name: "Malformed body", method: http.MethodPost, target: "/api/v1/search", body: `{"query": "hello"`,
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "Unknown body field", method: http.MethodPost, target: "/api/v1/search",
+			body: `{"query": "hello", "size": 5}`, wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "Data after body", method: http.MethodPost, target: "/api/v1/search", body: `{"query": "hello"} {}`,
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "No query", method: http.MethodPost, target: "/api/v1/search", body: `{"limit": 5}`,
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "Query of wrong type", method: http.MethodPost, target: "/api/v1/search", body: `{"query": 5}`,
+			wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "Unknown clause field", method: http.MethodPost, target: "/api/v1/search",
+			body: `{"query": {"word": "hello"}}`, wantStatus: http.StatusBadRequest, wantCode: codeInvalidBody,
+		},
+		{
+			name: "Body query syntax", method: http.MethodPost, target: "/api/v1/search", body: `{"query": "(hello"}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidQuery,
+		},
+		{
+			name: "Invalid clause", method: http.MethodPost, target: "/api/v1/search",
+			body:       `{"query": {"term": "hello", "phrase": "golang world"}}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidQuery,
+		},
+		{
+			name: "Invalid filter", method: http.MethodPost, target: "/api/v1/search",
+			body:       `{"query": "hello", "filters": {"ext": ""}}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidQuery,
+		},
+		{
+			name: "Body limit", method: http.MethodPost, target: "/api/v1/search",
+			body:       `{"query": "hello", "limit": 1000}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidParameter,
+		},
+		{
+			name: "Negative highlight", method: http.MethodPost, target: "/api/v1/search",
+			body:       `{"query": "hello", "highlight": {"count": -1}}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidParameter,
+		},
+		{
+			name: "Unknown body result field", method: http.MethodPost, target: "/api/v1/search",
+			body:       `{"query": "hello", "fields": ["owner"]}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codeInvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		f.Run(tt.name, func() {
+			recorder := f.do(tt.method, tt.target, tt.body)
+			require.Equal(f.T(), tt.wantStatus, recorder.Code, recorder.Body.String())
+
+			envelope := &errorEnvelope{}
+			require.NoError(f.T(), json.Unmarshal(recorder.Body.Bytes(), envelope))
+			require.Equal(f.T(), apiVersion, envelope.Version)
+			require.Equal(f.T(), tt.wantStatus, envelope.Error.Status)
+			require.Equal(f.T(), tt.wantCode, envelope.Error.Code)
+			require.NotEmpty(f.T(), envelope.Error.Message)
+		})
+	}
+}
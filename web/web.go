@@ -2,55 +2,103 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/go-chi/render"
 
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/polisgo2020/search-Arkronzxc/analysis"
 	"github.com/polisgo2020/search-Arkronzxc/config"
 	"github.com/polisgo2020/search-Arkronzxc/db"
 
 	"github.com/go-chi/chi"
 	"github.com/polisgo2020/search-Arkronzxc/index"
-	"github.com/polisgo2020/search-Arkronzxc/util"
+	"github.com/polisgo2020/search-Arkronzxc/query"
+	"github.com/polisgo2020/search-Arkronzxc/snippet"
+	"github.com/polisgo2020/search-Arkronzxc/suggest"
+	"github.com/polisgo2020/search-Arkronzxc/synonym"
 	"github.com/rs/zerolog/log"
 )
 
 type searchResponse struct {
-	Filename         string `json:"filename"`
-	WordsEncountered int    `json:"wordsEncountered"`
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+	// RelPath is the path of the file relative to the indexed directory
+	RelPath          string    `json:"relPath"`
+	Ext              string    `json:"ext"`
+	Title            string    `json:"title"`
+	Size             int64     `json:"size"`
+	ModTime          time.Time `json:"modTime"`
+	WordsEncountered int       `json:"wordsEncountered"`
+	Score            float64   `json:"score"`
+	// Snippets are the HTML fragments of the file with the matched words in <mark>
+	Snippets []string `json:"snippets"`
+}
+
+// maxPageSize is the maximum number of the search results in one response
+const maxPageSize = 100
+
+// searchResult is the answer of the search, missing are the query words which aren't in the index, suggestion is the
+// spelling-corrected query offered when nothing is found
+type searchResult struct {
+	Results []*searchResponse `json:"results"`
+	// Total is the number of all the matching files
+	Total int `json:"total"`
+	// Next is the cursor of the next page which is passed in the after parameter, it's empty on the last page
+	Next       string   `json:"next,omitempty"`
+	Missing    []string `json:"missing,omitempty"`
+	Suggestion string   `json:"suggestion,omitempty"`
 }
 
 type service struct {
-	repo *db.IndexRepository
+	repo          db.Store
+	ranking       index.BM25
+	synonyms      *synonym.File
+	synonymWeight float64
+	typoTolerant  bool
+	maxExpansions int
+	snippets      snippet.Options
+	pageSize      int
+	// sources is the directory the documents are added from, they can't be added if it's empty
+	sources string
+
+	// queries are the past queries which found something
+	queries      *suggest.Queries
+	suggestLimit int
+	// terms are the completions of the terms of dict, they are rebuilt when the store returns the new dictionary
+	termsMu sync.Mutex
+	terms   *suggest.Terms
+	dict    *index.Dictionary
+}
+
+// suggestResult is the answer of the autocomplete
+type suggestResult struct {
+	Suggestions []suggest.Suggestion `json:"suggestions"`
 }
 
 func (s *service) searchHandler(writer http.ResponseWriter, request *http.Request) {
 
 	writer.Header().Set("Content-Type", "application/json")
 
-	log.Info().Str("received", request.FormValue("search")).Msg("got request")
-
-	parsedSearchPhrase, err, errCode := parseSearchPhrase(request)
-	if err != nil {
-		log.Err(err).Int("status", errCode).Msg("error while parsing search phrase")
-		http.Error(writer, http.StatusText(errCode), errCode)
+	opts, apiErr := s.formOptions(request)
+	if apiErr != nil {
+		http.Error(writer, http.StatusText(apiErr.Status), apiErr.Status)
 		return
 	}
-
-
-	searchIndex, err := s.repo.GetIndex(parsedSearchPhrase)
-
-	resp, err, errCode := answerFormation(searchIndex, parsedSearchPhrase)
-	if err != nil {
-		log.Err(err).Int("status", errCode).Msg("error while creating answer")
-		http.Error(writer, http.StatusText(errCode), errCode)
+	answer, apiErr := s.search(opts)
+	if apiErr != nil {
+		http.Error(writer, http.StatusText(apiErr.Status), apiErr.Status)
 		return
 	}
-
+	resp := answer.result
 
 	finalJson, err := json.Marshal(resp)
 	if err != nil {
@@ -60,7 +108,7 @@ func (s *service) searchHandler(writer http.ResponseWriter, request *http.Reques
 	}
 
 	log.Debug().
-		Strs("parse search phrase", parsedSearchPhrase).
+		Strs("parse search phrase", answer.terms).
 		Interface("resp", resp).
 		Interface("final json", finalJson).
 		Msg("search phrase parsed")
@@ -74,61 +122,576 @@ func (s *service) searchHandler(writer http.ResponseWriter, request *http.Reques
 	}
 }
 
-func parseSearchPhrase(request *http.Request) ([]string, error, int) {
+// searchOptions are the options of the search taken from the query parameters or from the JSON body
+type searchOptions struct {
+	// phrase is the query in the query syntax, it's empty if the query is the structured clause
+	phrase string
+	clause *query.Clause
+	// lang is the name of the query language, it's detected if it's empty
+	lang         string
+	typoTolerant bool
+	page         query.Page
+	// snippets are the options of the snippets, the snippets aren't made if it's nil
+	snippets *snippet.Options
+	// filters restrict the query in addition to the filters written in it
+	filters []query.Filter
+}
+
+// text returns the words of the query
+func (o *searchOptions) text() string {
+	if o.clause != nil {
+		return o.clause.Text()
+	}
+	return o.phrase
+}
+
+// formOptions returns the search options from the query parameters. The fuzzy parameter enables or disables the typo
+// tolerance configured for the server
+func (s *service) formOptions(request *http.Request) (*searchOptions, *apiError) {
+	opts := &searchOptions{
+		phrase:       request.FormValue("search"),
+		lang:         request.FormValue("lang"),
+		typoTolerant: s.typoTolerant,
+	}
+
+	if fuzzy := request.FormValue("fuzzy"); fuzzy != "" {
+		var err error
+		if opts.typoTolerant, err = strconv.ParseBool(fuzzy); err != nil {
+			log.Err(err).Msg("error while parsing fuzzy parameter")
+			return nil, newAPIError(http.StatusBadRequest, fmt.Errorf("invalid fuzzy parameter %q", fuzzy))
+		}
+	}
+
+	snippets, err := s.snippetOptions(request)
+	if err != nil {
+		log.Err(err).Msg("error while parsing snippet options")
+		return nil, newAPIError(http.StatusBadRequest, err)
+	}
+	opts.snippets = &snippets
+
+	if opts.page, err = s.page(request); err != nil {
+		log.Err(err).Msg("error while parsing page")
+		return nil, newAPIError(http.StatusBadRequest, err)
+	}
+	return opts, nil
+}
+
+// searchAnswer is the result of the search together with the query it's found by
+type searchAnswer struct {
+	result *searchResult
+	// terms are the analyzed terms of the query including the expanded ones
+	terms []string
+	// stopWords are the query words dropped by the analyzer
+	stopWords []string
+}
+
+// search parses the query, finds the page of the results and offers the spelling suggestion if nothing is found.
+// It's shared by all the versions of the search API
+func (s *service) search(opts *searchOptions) (*searchAnswer, *apiError) {
+
+	log.Info().Str("received", opts.text()).Msg("got request")
+
+	settings, analyzer, err, errCode := s.analyzer(opts.lang, opts.text())
+	if err != nil {
+		log.Err(err).Int("status", errCode).Msg("error while creating analyzer")
+		return nil, newAPIError(errCode, err)
+	}
+
+	parsedQuery, err, errCode := s.parseSearchPhrase(opts, settings, analyzer)
+	if err != nil {
+		log.Err(err).Int("status", errCode).Msg("error while parsing search phrase")
+		return nil, newAPIError(errCode, err)
+	}
+
+	var stopWords []string
+	if opts.clause != nil {
+		stopWords, err = query.ClauseStopWords(opts.clause, analyzer)
+	} else {
+		stopWords, err = query.StopWords(opts.phrase, analyzer)
+	}
+	if err != nil {
+		log.Err(err).Msg("error while finding stop words")
+		return nil, newAPIError(http.StatusInternalServerError, err)
+	}
+
+	dict, err := s.repo.Dictionary()
+	if err != nil {
+		log.Err(err).Msg("error while getting term dictionary")
+		return nil, newAPIError(http.StatusInternalServerError, err)
+	}
+	query.Expand(parsedQuery, dict, s.maxExpansions)
 
-	log.Debug().Interface("request", request)
+	terms := query.Terms(parsedQuery)
+	searchIndex, missing, err := s.repo.GetIndex(terms, query.NeedsAllDocuments(parsedQuery))
+	if err != nil {
+		log.Err(err).Msg("error while getting index")
+		return nil, newAPIError(http.StatusInternalServerError, err)
+	}
 
-	searchPhrase := request.FormValue("search")
-	log.Debug().Str("search phrase", searchPhrase)
+	resp, err, errCode := answerFormation(searchIndex, parsedQuery, s.ranking, opts.page, opts.snippets)
+	if err != nil {
+		log.Err(err).Int("status", errCode).Msg("error while creating answer")
+		return nil, newAPIError(errCode, err)
+	}
 
-	rawUserInput := strings.ToLower(searchPhrase)
-	log.Debug().Str("raw user input", rawUserInput)
+	// the missing terms are reported as the words typed by the users instead of the analyzed terms
+	if opts.clause != nil {
+		resp.Missing, err = query.ClauseMissingWords(opts.clause, analyzer, missing)
+	} else {
+		resp.Missing, err = query.MissingWords(opts.phrase, analyzer, missing)
+	}
+	if err != nil {
+		log.Err(err).Msg("error while finding missing words")
+		return nil, newAPIError(http.StatusInternalServerError, err)
+	}
 
-	parsedUserInput := strings.Split(rawUserInput, " ")
-	log.Debug().Strs("parsed user input", parsedUserInput)
+	// only the query strings typed by the users are completed and corrected, the words of the clause aren't a query
+	switch {
+	case opts.clause != nil:
+	case resp.Total > 0:
+		s.queries.Add(opts.phrase)
+	default:
+		resp.Suggestion = s.suggestion(opts.phrase, analyzer, dict)
+	}
+
+	if terms == nil {
+		terms = []string{}
+	}
+	return &searchAnswer{result: resp, terms: terms, stopWords: stopWords}, nil
+}
 
-	cleanedUserInput := make([]string, 0, len(parsedUserInput))
-	for i := range parsedUserInput {
-		w, err := util.CleanUserData(parsedUserInput[i])
+// analyzer returns the analysis settings of the index and its analyzer for the language of the search phrase
+func (s *service) analyzer(lang, phrase string) (analysis.Settings, analysis.Analyzer, error, int) {
+	settings, err := s.repo.Settings()
+	if err != nil {
+		return settings, nil, fmt.Errorf("error while getting index settings: %w", err), http.StatusInternalServerError
+	}
+	language, err := settings.LanguageOf(lang, phrase)
+	if err != nil {
+		return settings, nil, err, http.StatusBadRequest
+	}
+	analyzer, err := settings.New(language)
+	if err != nil {
+		return settings, nil, fmt.Errorf("error while creating analyzer: %w", err), http.StatusInternalServerError
+	}
+	return settings, analyzer, nil, -1
+}
+
+// suggestion returns the search phrase with the misspelled words corrected by the terms of the dictionary or the empty
+// string if there is nothing to correct
+func (s *service) suggestion(phrase string, analyzer analysis.Analyzer, dict *index.Dictionary) string {
+	corrected, ok := query.Correct(phrase, analyzer, dict)
+	if !ok {
+		return ""
+	}
+	log.Debug().Str("suggestion", corrected).Msg("spelling suggestion")
+	return corrected
+}
+
+// parseSearchPhrase parses the query string or the structured query with the analyzer of the index and restricts it
+// by the filters of the options
+func (s *service) parseSearchPhrase(opts *searchOptions, settings analysis.Settings,
+	analyzer analysis.Analyzer) (query.Node, error, int) {
+
+	log.Debug().Str("search phrase", opts.text())
+
+	parser := &query.Parser{
+		Analyzer:      analyzer,
+		Settings:      settings,
+		Synonyms:      s.synonyms.Dictionary(),
+		SynonymWeight: s.synonymWeight,
+		TypoTolerant:  opts.typoTolerant,
+	}
+	var parsedQuery query.Node
+	var err error
+	if opts.clause != nil {
+		parsedQuery, err = parser.ParseClause(opts.clause)
+	} else {
+		parsedQuery, err = parser.Parse(opts.phrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing query: %w", err), http.StatusBadRequest
+	}
+	parsedQuery = query.WithFilters(parsedQuery, opts.filters)
+
+	log.Debug().Interface("parsed query", parsedQuery).Msg("user input parsed")
+
+	return parsedQuery, nil, -1
+}
+
+// snippetOptions returns the snippet options of the server overridden by the snippetSize and snippets parameters
+func (s *service) snippetOptions(request *http.Request) (snippet.Options, error) {
+	opts := s.snippets
+	for param, value := range map[string]*int{"snippetSize": &opts.FragmentSize, "snippets": &opts.Count} {
+		v := request.FormValue(param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("invalid %s parameter %q", param, v)
+		}
+		*value = n
+	}
+	return opts, nil
+}
+
+// page returns the page of the results from the limit, offset, after and sort parameters
+func (s *service) page(request *http.Request) (query.Page, error) {
+	limit, offset := s.pageSize, 0
+	for param, value := range map[string]*int{"limit": &limit, "offset": &offset} {
+		v := request.FormValue(param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
 		if err != nil {
-			err = fmt.Errorf("error while cleaning each word in query: %w", err)
+			return query.Page{}, fmt.Errorf("invalid %s parameter %q", param, v)
+		}
+		*value = n
+	}
+	return newPage(limit, offset, request.FormValue("after"), request.FormValue("sort"))
+}
 
-			return nil, err, http.StatusBadRequest
+// newPage returns the page of the results after validating its limit, offset, cursor and order, the cursor must be made
+// in the same order
+func newPage(limit, offset int, after, order string) (query.Page, error) {
+	if limit <= 0 || limit > maxPageSize {
+		return query.Page{}, fmt.Errorf("limit must be from 1 to %d", maxPageSize)
+	}
+	if offset < 0 {
+		return query.Page{}, errors.New("offset must not be negative")
+	}
+
+	sort, err := query.ParseSort(order)
+	if err != nil {
+		return query.Page{}, err
+	}
+
+	page := query.Page{Limit: limit, Offset: offset, Sort: sort}
+	if after != "" {
+		cursor, err := query.ParseCursor(after)
+		if err != nil {
+			return page, err
 		}
-		if w != "" {
-			cleanedUserInput = append(cleanedUserInput, w)
+		if err := cursor.Check(sort); err != nil {
+			return page, err
 		}
+		page.After = cursor
 	}
+	return page, nil
+}
 
-	log.Debug().Strs("clean user input: ", cleanedUserInput).Msg("user input parsed")
+// answerFormation returns the page of the results of the query with the snippets of the found files, the snippets
+// aren't made if their options are nil
+func answerFormation(index *index.Index, parsedQuery query.Node, ranking index.BM25, page query.Page,
+	snippets *snippet.Options) (*searchResult, error, int) {
 
-	return cleanedUserInput, nil, -1
+	log.Debug().Interface("index", index).Interface("parsed query", parsedQuery)
+
+	hits, total := query.SearchPage(index, parsedQuery, ranking, page)
+
+	log.Debug().Interface("hits", hits).Msg("answer")
+
+	terms := make(map[string]struct{})
+	for _, t := range query.PositiveTerms(parsedQuery) {
+		terms[t] = struct{}{}
+	}
+	analyzers := make(map[analysis.Language]analysis.Analyzer)
+
+	resp := make([]*searchResponse, 0, len(hits))
+	for _, h := range hits {
+		r := &searchResponse{
+			ID:               h.Document.ID,
+			Filename:         h.Document.Path,
+			RelPath:          h.Document.RelPath,
+			Ext:              h.Document.Ext,
+			Title:            h.Document.Title,
+			Size:             h.Document.Size,
+			ModTime:          h.Document.ModTime,
+			WordsEncountered: h.WordsEncountered,
+			Score:            h.Score,
+			Snippets:         []string{},
+		}
+		if snippets != nil {
+			r.Snippets = makeSnippets(index, h.Document, terms, analyzers, *snippets)
+		}
+		resp = append(resp, r)
+	}
+
+	log.Debug().Interface("search response", resp).Msg("search response created")
+
+	result := &searchResult{
+		Results: resp,
+		Total:   total,
+	}
+	// the number of the hits after the cursor is unknown, so the page after the cursor may be the last one
+	if len(hits) == page.Limit && (page.After != nil || page.Offset+len(hits) < total) {
+		result.Next = query.CursorOf(hits[len(hits)-1], page.Sort).String()
+	}
+	return result, nil, -1
+}
+
+// makeSnippets returns the snippets of the document or no snippets if the file can't be read, analyzers are cached by
+// the document language
+func makeSnippets(idx *index.Index, doc *index.Document, terms map[string]struct{},
+	analyzers map[analysis.Language]analysis.Analyzer, opts snippet.Options) []string {
+
+	analyzer, ok := analyzers[doc.Language]
+	if !ok {
+		var err error
+		if analyzer, err = idx.Settings.New(doc.Language); err != nil {
+			log.Err(err).Str("language", string(doc.Language)).Msg("error while creating analyzer for snippets")
+			return []string{}
+		}
+		analyzers[doc.Language] = analyzer
+	}
+
+	snippets, err := snippet.FromFile(doc.Path, analyzer, terms, opts)
+	if err != nil {
+		log.Err(err).Str("filename", doc.Path).Msg("error while making snippets")
+		return []string{}
+	}
+	return snippets
 }
 
-func answerFormation(index *index.Index, cleanedUserInput []string) ([]*searchResponse, error, int) {
+// documentID returns the document ID from the URL
+func documentID(request *http.Request) (int, error) {
+	return strconv.Atoi(chi.URLParam(request, "id"))
+}
 
-	log.Debug().Interface("index", index).Strs("cleaned user input", cleanedUserInput)
+// deleteDocumentHandler removes the document from the index
+func (s *service) deleteDocumentHandler(writer http.ResponseWriter, request *http.Request) {
 
-	ans, err := index.BuildSearchIndex(cleanedUserInput)
+	id, err := documentID(request)
 	if err != nil {
-		err = fmt.Errorf("error while building search index with cleaned user input: %w", err)
-		return nil, err, http.StatusInternalServerError
+		log.Err(err).Msg("error while parsing document id")
+		http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
 	}
 
-	log.Debug().Interface("answer", ans).Msg("answer")
+	log.Info().Int("id", id).Msg("removing document")
 
+	if err := s.repo.RemoveDocument(id); errors.Is(err, db.ErrNotFound) {
+		http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Err(err).Int("id", id).Msg("error while removing document")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 
-	var resp []*searchResponse
-	for s := range ans {
-		resp = append(resp, &searchResponse{
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// putDocumentHandler reads the document from disk and reindexes it
+func (s *service) putDocumentHandler(writer http.ResponseWriter, request *http.Request) {
 
-			Filename:         s,
-			WordsEncountered: ans[s],
-		})
+	id, err := documentID(request)
+	if err != nil {
+		log.Err(err).Msg("error while parsing document id")
+		http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
 	}
 
-	log.Debug().Interface("search response", resp).Msg("search response created")
-	return resp, nil, -1
+	log.Info().Int("id", id).Msg("updating document")
+
+	doc, err := s.repo.UpdateDocument(id)
+	if errors.Is(err, db.ErrNotFound) || os.IsNotExist(err) {
+		http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Err(err).Int("id", id).Msg("error while updating document")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	render.JSON(writer, request, doc)
+}
+
+// documentBody is the JSON body of the added document, path is relative to the sources directory
+type documentBody struct {
+	Path string `json:"path"`
+}
+
+// errOutsideSources is returned when the path of the added document leads out of the sources directory
+var errOutsideSources = errors.New("path is outside the sources directory")
+
+// sourcePath returns the path of the file of the sources directory joined the same way the directory is walked. The
+// path is rejected if it leaves the directory by ".." or by the symbolic links
+func sourcePath(root, rel string) (string, error) {
+	filename := filepath.Join(root, rel)
+	if !inside(filepath.Clean(root), filename) {
+		return "", errOutsideSources
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	realFile, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return "", err
+	}
+	if !inside(realRoot, realFile) {
+		return "", errOutsideSources
+	}
+	return filename, nil
+}
+
+// inside reports whether the cleaned path is in the directory and isn't the directory itself
+func inside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// addDocumentHandler indexes the file of the sources directory, the file is added if it isn't indexed yet and
+// reindexed keeping its ID otherwise
+func (s *service) addDocumentHandler(writer http.ResponseWriter, request *http.Request) {
+
+	if s.sources == "" {
+		http.Error(writer, "sources directory isn't configured", http.StatusNotFound)
+		return
+	}
+
+	var body documentBody
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxBodySize)).Decode(&body); err != nil ||
+		body.Path == "" {
+		log.Err(err).Msg("error while decoding document body")
+		http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	filename, err := sourcePath(s.sources, body.Path)
+	if errors.Is(err, errOutsideSources) {
+		log.Warn().Str("path", body.Path).Msg("document outside the sources directory is rejected")
+		http.Error(writer, err.Error(), http.StatusForbidden)
+		return
+	} else if os.IsNotExist(err) {
+		http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Err(err).Str("path", body.Path).Msg("error while resolving document path")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info().Str("filename", filename).Msg("adding document")
+
+	doc, added, err := db.AddDocument(s.repo, filename, s.sources)
+	if errors.Is(err, db.ErrNotFound) || os.IsNotExist(err) {
+		http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Err(err).Str("filename", filename).Msg("error while adding document")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if added {
+		render.Status(request, http.StatusCreated)
+	}
+	render.JSON(writer, request, doc)
+}
+
+// statsHandler returns the statistics of the index
+func (s *service) statsHandler(writer http.ResponseWriter, request *http.Request) {
+
+	stats, err := s.repo.Stats()
+	if err != nil {
+		log.Err(err).Msg("error while getting index stats")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	render.JSON(writer, request, stats)
+}
+
+// reloadSynonymsHandler reads the synonym dictionary again, the queries are expanded with the new synonyms right away
+func (s *service) reloadSynonymsHandler(writer http.ResponseWriter, request *http.Request) {
+
+	if s.synonyms == nil {
+		http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if err := s.synonyms.Reload(); err != nil {
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// suggestHandler returns the completions of the typed prefix: the past queries starting with it and the whole prefix
+// with its last word completed by the indexed terms
+func (s *service) suggestHandler(writer http.ResponseWriter, request *http.Request) {
+
+	prefix := request.FormValue("prefix")
+
+	limit := s.suggestLimit
+	if l := request.FormValue("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			log.Err(err).Str("limit", l).Msg("error while parsing limit")
+			http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if n < limit {
+			limit = n
+		}
+	}
+
+	result := &suggestResult{Suggestions: []suggest.Suggestion{}}
+	if suggest.Normalize(prefix) == "" {
+		render.JSON(writer, request, result)
+		return
+	}
+
+	terms, err := s.termCompletions()
+	if err != nil {
+		log.Err(err).Msg("error while getting term completions")
+		http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	seen := make(map[string]struct{})
+	for _, q := range s.queries.Complete(prefix, limit) {
+		seen[q.Text] = struct{}{}
+		result.Suggestions = append(result.Suggestions, q)
+	}
+
+	rest, word := suggest.LastWord(prefix)
+	if word != "" {
+		for _, t := range terms.Complete(word, limit) {
+			if len(result.Suggestions) >= limit {
+				break
+			}
+			t.Text = suggest.Normalize(rest + t.Text)
+			if _, ok := seen[t.Text]; !ok {
+				seen[t.Text] = struct{}{}
+				result.Suggestions = append(result.Suggestions, t)
+			}
+		}
+	}
+
+	render.JSON(writer, request, result)
+}
+
+// termCompletions returns the completions of the terms of the current term dictionary
+func (s *service) termCompletions() (*suggest.Terms, error) {
+	dict, err := s.repo.Dictionary()
+	if err != nil {
+		return nil, err
+	}
+
+	s.termsMu.Lock()
+	defer s.termsMu.Unlock()
+
+	if s.terms == nil || s.dict != dict {
+		s.terms = suggest.NewTerms(dict, s.suggestLimit)
+		s.dict = dict
+	}
+	return s.terms, nil
 }
 
 func logMiddleware(next http.Handler) http.Handler {
@@ -145,29 +708,67 @@ func logMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// StartingWeb starts the search server, synonyms is the synonym dictionary of the queries and may be nil
+func StartingWeb(repo db.Store, synonyms *synonym.File, c *config.Config) error {
+	r := newRouter(newService(repo, synonyms, c))
 
-func StartingWeb(idx *index.Index, c *config.Config) error {
-	s := &service{
-		idx: idx,
+	if err := http.ListenAndServe(c.Listen, r); err != nil {
+		log.Err(err)
+		return err
 	}
+	log.Info().Msgf("started to listen at interface %s", c.Listen)
+
+	return nil
+}
+
+// newService returns the search service of the store configured by the config
+func newService(repo db.Store, synonyms *synonym.File, c *config.Config) *service {
+	return &service{
+		repo: repo,
+		ranking: index.BM25{
+			K1: c.BM25K1,
+			B:  c.BM25B,
+		},
+		synonyms:      synonyms,
+		synonymWeight: c.SynonymWeight,
+		typoTolerant:  c.TypoTolerant,
+		maxExpansions: c.MaxExpansions,
+		queries:       suggest.NewQueries(c.QueryLogSize),
+		suggestLimit:  c.SuggestLimit,
+		snippets: snippet.Options{
+			FragmentSize: c.SnippetSize,
+			Count:        c.SnippetCount,
+		},
+		pageSize: c.PageSize,
+		sources:  c.SourcesDir,
+	}
+}
+
+// newRouter returns the routes of the API and the static files
+func newRouter(s *service) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(logMiddleware)
 	r.Route("/api", func(r chi.Router) {
 		r.Use(render.SetContentType(render.ContentTypeJSON))
 		r.Get("/", s.searchHandler)
+		r.Get("/stats", s.statsHandler)
+		r.Get("/suggest", s.suggestHandler)
+		r.Delete("/documents/{id}", s.deleteDocumentHandler)
+		r.Put("/documents/{id}", s.putDocumentHandler)
+		r.Post("/documents", s.addDocumentHandler)
+		r.Post("/synonyms/reload", s.reloadSynonymsHandler)
+	})
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(render.SetContentType(render.ContentTypeJSON))
+		r.NotFound(notFoundHandler)
+		r.MethodNotAllowed(methodNotAllowedHandler)
+		r.Get("/search", s.searchV1Handler)
+		r.Post("/search", s.searchBodyHandler)
 	})
 	r.Get("/*", func(writer http.ResponseWriter, request *http.Request) {
 		h := http.FileServer(http.Dir("./static"))
 		h.ServeHTTP(writer, request)
 	})
-
-
-	if err := http.ListenAndServe(c.Listen, r); err != nil {
-		log.Err(err)
-		return err
-	}
-	log.Info().Msgf("started to listen at interface %s", c.Listen)
-
-	return nil
+	return r
 }
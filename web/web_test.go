@@ -0,0 +1,41 @@
+package web
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourcePath(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "testDir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "sources")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "sub", "a.txt"), []byte("hello"), 0644))
+	secret := filepath.Join(dir, "secret.txt")
+	require.NoError(t, ioutil.WriteFile(secret, []byte("secret"), 0644))
+	abs, err := filepath.Abs(secret)
+	require.NoError(t, err)
+	require.NoError(t, os.Symlink(abs, filepath.Join(root, "link.txt")))
+
+	filename, err := sourcePath(root, "sub/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "sub", "a.txt"), filename)
+
+	filename, err = sourcePath(root, "/sub/../sub/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "sub", "a.txt"), filename)
+
+	for _, rel := range []string{"../secret.txt", "sub/../../secret.txt", "link.txt", ".", "/"} {
+		_, err := sourcePath(root, rel)
+		require.Equal(t, errOutsideSources, err, rel)
+	}
+
+	_, err = sourcePath(root, "missing.txt")
+	require.True(t, os.IsNotExist(err))
+}